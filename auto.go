@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const (
+	DefaultAutoMinPanes = 2
+)
+
+// autoEnabled reports whether @focus-zoom-auto is on for the current window
+func autoEnabled() bool {
+	return isOptionOn(GetOption("@focus-zoom-auto", ""))
+}
+
+// GetAutoMinPanes returns @focus-zoom-auto-min-panes, the number of panes a window
+// needs before it's enabled automatically. Falls back to DefaultAutoMinPanes.
+func GetAutoMinPanes() int {
+	value, ok := lookupOption("@focus-zoom-auto-min-panes", "", func(v string) bool {
+		n, err := strconv.Atoi(v)
+		return err == nil && n >= 1
+	})
+	if !ok {
+		return DefaultAutoMinPanes
+	}
+	n, _ := strconv.Atoi(value)
+	return n
+}
+
+// autoSessionMatches reports whether a session name matches one of the glob
+// patterns in @focus-zoom-auto-sessions. No patterns matches every session.
+func autoSessionMatches(session, patterns string) bool {
+	fields := strings.FieldsFunc(patterns, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+	if len(fields) == 0 {
+		return true
+	}
+	for _, pattern := range fields {
+		if ok, _ := path.Match(pattern, session); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// CurrentWindowState returns the zoom state for the current window, or nil if zoom
+// is off there. Windows meeting the @focus-zoom-auto criteria, and windows in global
+// mode not yet zoomed, are captured on the fly: their layout is kept as the snapshot
+// and a window entry is saved.
+func CurrentWindowState(state *State) (*State, error) {
+	ref, err := GetCurrentWindowRef()
+	if err != nil {
+		return nil, fmt.Errorf("GetCurrentWindowRef: %w", err)
+	}
+	windowState := state.ForWindow(ref)
+	if windowState != nil && windowState.Snapshot != "" {
+		return windowState, nil
+	}
+
+	// Global mode zooms any window with something to zoom; auto mode has its own criteria
+	minPanes := 2
+	if windowState == nil {
+		if _, ok := state.Windows[ref.ID]; ok {
+			// Toggled off by hand
+			return nil, nil
+		}
+		if !autoEnabled() || !autoSessionMatches(ref.Session, GetOption("@focus-zoom-auto-sessions", "")) {
+			return nil, nil
+		}
+		minPanes = GetAutoMinPanes()
+	}
+	count, err := GetPaneCount()
+	if err != nil {
+		return nil, fmt.Errorf("GetPaneCount: %w", err)
+	}
+	if count < minPanes {
+		return nil, nil
+	}
+
+	snapshot, err := GetWindowLayout()
+	if err != nil {
+		return nil, fmt.Errorf("GetWindowLayout: %w", err)
+	}
+	debugf("Capturing window %s (%s:%s) with %d panes", ref.ID, ref.Session, ref.Window, count)
+
+	pruneWindows(state)
+	if state.Windows == nil {
+		state.Windows = make(map[string]*WindowState)
+	}
+	state.Windows[ref.ID] = &WindowState{Session: ref.Session, Window: ref.Window, Snapshot: snapshot}
+	if err := SaveState(state); err != nil {
+		return nil, fmt.Errorf("SaveState: %w", err)
+	}
+	if err := publishActive(ref.ID, true, state.Global); err != nil {
+		return nil, fmt.Errorf("publishActive: %w", err)
+	}
+	return state.ForWindow(ref), nil
+}
+
+// pruneWindows drops window entries for windows that no longer exist
+func pruneWindows(state *State) {
+	if len(state.Windows) == 0 {
+		return
+	}
+	live, err := ListWindowIDs()
+	if err != nil {
+		debugf("pruneWindows: ListWindowIDs error (ignored): %v", err)
+		return
+	}
+	for id := range state.Windows {
+		if !live[id] {
+			delete(state.Windows, id)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestAutoSessionMatches(t *testing.T) {
+	tests := []struct {
+		session  string
+		patterns string
+		expected bool
+	}{
+		{"main", "", true},
+		{"work", "work*", true},
+		{"work-api", "home, work*", true},
+		{"scratch", "home,work*", false},
+	}
+
+	for _, tc := range tests {
+		if got := autoSessionMatches(tc.session, tc.patterns); got != tc.expected {
+			t.Errorf("autoSessionMatches(%q, %q) = %v, want %v", tc.session, tc.patterns, got, tc.expected)
+		}
+	}
+}
+
+func TestGetAutoMinPanes(t *testing.T) {
+	fake := newFakeTmux(t)
+
+	if got := GetAutoMinPanes(); got != DefaultAutoMinPanes {
+		t.Errorf("unset: got %d, want %d", got, DefaultAutoMinPanes)
+	}
+
+	fake.set("global", "@focus-zoom-auto-min-panes", "3")
+	fake.set("window", "@focus-zoom-auto-min-panes", "0")
+	if got := GetAutoMinPanes(); got != 3 {
+		t.Errorf("invalid window value: got %d, want 3", got)
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+const (
+	bookmarksFile = "bookmarks.json"
+)
+
+// Bookmarks holds named layouts per window: window ID -> name -> layout string.
+// Window IDs survive renaming and renumbering, like State.Windows.
+type Bookmarks map[string]map[string]string
+
+// bookmarksFilePath returns the full path to the bookmarks file
+func bookmarksFilePath() string {
+	return filepath.Join(configDir(), bookmarksFile)
+}
+
+// LoadBookmarks reads the saved bookmarks from disk
+func LoadBookmarks() (Bookmarks, error) {
+	data, err := os.ReadFile(bookmarksFilePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Bookmarks{}, nil
+		}
+		return nil, err
+	}
+
+	bookmarks := Bookmarks{}
+	if err := json.Unmarshal(data, &bookmarks); err != nil {
+		return nil, err
+	}
+	return bookmarks, nil
+}
+
+// SaveBookmarks writes the bookmarks to disk
+func SaveBookmarks(bookmarks Bookmarks) error {
+	if err := ensureConfigDir(); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(bookmarks, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(bookmarksFilePath(), data, 0644)
+}
+
+// Set stores a layout under name for a window
+func (b Bookmarks) Set(windowID, name, layout string) {
+	if b[windowID] == nil {
+		b[windowID] = map[string]string{}
+	}
+	b[windowID][name] = layout
+}
+
+// Get returns the layout saved under name for a window
+func (b Bookmarks) Get(windowID, name string) (string, bool) {
+	layout, ok := b[windowID][name]
+	return layout, ok
+}
+
+// Names returns the sorted bookmark names for a window
+func (b Bookmarks) Names(windowID string) []string {
+	var names []string
+	for name := range b[windowID] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// prune drops the bookmarks of windows that no longer exist
+func (b Bookmarks) prune() {
+	live, err := ListWindowIDs()
+	if err != nil {
+		debugf("Bookmarks.prune: ListWindowIDs error (ignored): %v", err)
+		return
+	}
+	for id := range b {
+		if !live[id] {
+			delete(b, id)
+		}
+	}
+}
+
+// cmdSave stores the current window layout under a name
+func cmdSave(args []string) error {
+	if len(args) != 1 || args[0] == "" {
+		return fmt.Errorf("usage: tmux-focus-zoom save <name>")
+	}
+	name := args[0]
+
+	ref, err := GetCurrentWindowRef()
+	if err != nil {
+		return fmt.Errorf("GetCurrentWindowRef: %w", err)
+	}
+	snapshot, err := CaptureSnapshot()
+	if err != nil {
+		return fmt.Errorf("CaptureSnapshot: %w", err)
+	}
+
+	bookmarks, err := LoadBookmarks()
+	if err != nil {
+		return fmt.Errorf("LoadBookmarks: %w", err)
+	}
+	bookmarks.prune()
+	bookmarks.Set(ref.ID, name, snapshot.Snapshot)
+	debugf("cmdSave: %s for %s = %s", name, ref.ID, snapshot.Snapshot)
+
+	if err := SaveBookmarks(bookmarks); err != nil {
+		return fmt.Errorf("SaveBookmarks: %w", err)
+	}
+	return DisplayMessage(fmt.Sprintf("Focus zoom: saved layout %q", name))
+}
+
+// cmdRestore applies a saved layout to the current window
+func cmdRestore(args []string) error {
+	if len(args) != 1 || args[0] == "" {
+		return fmt.Errorf("usage: tmux-focus-zoom restore <name>")
+	}
+	name := args[0]
+
+	ref, err := GetCurrentWindowRef()
+	if err != nil {
+		return fmt.Errorf("GetCurrentWindowRef: %w", err)
+	}
+
+	bookmarks, err := LoadBookmarks()
+	if err != nil {
+		return fmt.Errorf("LoadBookmarks: %w", err)
+	}
+	layout, ok := bookmarks.Get(ref.ID, name)
+	if !ok {
+		if err := DisplayMessage(fmt.Sprintf("Focus zoom: no layout named %q", name)); err != nil {
+			return err
+		}
+		return fmt.Errorf("no layout named %q", name)
+	}
+
+	// Panes may have been added, closed or replaced since saving
+	mode, err := RestoreSnapshotForPanes(&State{Snapshot: layout})
+	if err != nil {
+		return fmt.Errorf("RestoreSnapshotForPanes: %w", err)
+	}
+	debugf("cmdRestore: %s = %s (%s)", name, layout, mode)
+	if mode == restoreSkip {
+		if err := DisplayMessage(fmt.Sprintf("Focus zoom: %q has no panes in common with this window", name)); err != nil {
+			return err
+		}
+		return fmt.Errorf("layout %q has no panes in common with this window", name)
+	}
+	return DisplayMessage(fmt.Sprintf("Focus zoom: restored layout %q", name))
+}
+
+// cmdList prints the saved layout names for the current window
+func cmdList() error {
+	ref, err := GetCurrentWindowRef()
+	if err != nil {
+		return fmt.Errorf("GetCurrentWindowRef: %w", err)
+	}
+
+	bookmarks, err := LoadBookmarks()
+	if err != nil {
+		return fmt.Errorf("LoadBookmarks: %w", err)
+	}
+
+	for _, name := range bookmarks.Names(ref.ID) {
+		layout, _ := bookmarks.Get(ref.ID, name)
+		panes := 0
+		if tree, err := ParseLayout(layout); err == nil {
+			panes = countPanes(tree)
+		}
+		fmt.Printf("%s\t%d panes\n", name, panes)
+	}
+	return nil
+}
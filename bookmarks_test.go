@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestBookmarksRoundTrip(t *testing.T) {
+	t.Setenv("FOCUS_ZOOM_CONFIG_DIR", t.TempDir())
+
+	bookmarks, err := LoadBookmarks()
+	if err != nil {
+		t.Fatalf("LoadBookmarks with no file failed: %v", err)
+	}
+	if len(bookmarks) != 0 {
+		t.Fatalf("expected no bookmarks, got %v", bookmarks)
+	}
+
+	bookmarks.Set("@1", "review", "layout-a")
+	bookmarks.Set("@1", "coding", "layout-b")
+	bookmarks.Set("@2", "review", "layout-c")
+	if err := SaveBookmarks(bookmarks); err != nil {
+		t.Fatalf("SaveBookmarks failed: %v", err)
+	}
+
+	loaded, err := LoadBookmarks()
+	if err != nil {
+		t.Fatalf("LoadBookmarks failed: %v", err)
+	}
+
+	if layout, ok := loaded.Get("@1", "review"); !ok || layout != "layout-a" {
+		t.Errorf("@1 review: got %q, %v; want layout-a", layout, ok)
+	}
+	if layout, ok := loaded.Get("@2", "review"); !ok || layout != "layout-c" {
+		t.Errorf("@2 review: got %q, %v; want layout-c", layout, ok)
+	}
+	if _, ok := loaded.Get("@3", "review"); ok {
+		t.Error("bookmarks should be scoped per window")
+	}
+
+	names := loaded.Names("@1")
+	if len(names) != 2 || names[0] != "coding" || names[1] != "review" {
+		t.Errorf("Names: got %v, want [coding review]", names)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// cmdCalc zooms a layout string given on the command line or stdin, without tmux
+func cmdCalc(args []string) error {
+	fs := flag.NewFlagSet("calc", flag.ContinueOnError)
+	layout := fs.String("layout", "", "layout string to zoom (default: read from stdin)")
+	pane := fs.String("pane", "", "pane to zoom, e.g. 42 or %42 (required)")
+	percent := fs.Int("percent", DefaultZoomPercent, "zoom percentage")
+	format := fs.String("format", "layout", "output format: layout, json or diagram")
+	width := fs.Int("width", defaultRenderWidth, "diagram width in columns")
+	pinnedList := fs.String("pinned", "", "comma-separated panes that keep their size, e.g. 3,%5")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *pane == "" {
+		return fmt.Errorf("--pane is required")
+	}
+	paneID, err := parsePaneID(*pane)
+	if err != nil {
+		return fmt.Errorf("invalid pane %q: %w", *pane, err)
+	}
+
+	if *layout == "" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("reading stdin: %w", err)
+		}
+		*layout = string(data)
+	}
+
+	pinned := make(map[int]bool)
+	for _, p := range strings.FieldsFunc(*pinnedList, func(r rune) bool { return r == ',' || r == ' ' }) {
+		id, err := parsePaneID(p)
+		if err != nil {
+			return fmt.Errorf("invalid pinned pane %q: %w", p, err)
+		}
+		pinned[id] = true
+	}
+
+	opts := zoomOptions{Percent: *percent, Pinned: pinned}
+	out, err := calcLayout(strings.TrimSpace(*layout), paneID, opts, *format, *width)
+	if err != nil {
+		return err
+	}
+	fmt.Print(out)
+	return nil
+}
+
+// calcLayout zooms layout on paneID and formats the result
+func calcLayout(layout string, paneID int, opts zoomOptions, format string, width int) (string, error) {
+	if !validZoomPercent(opts.Percent) {
+		return "", fmt.Errorf("percent must be between %d and %d, got %d", MinZoomPercent, MaxZoomPercent, opts.Percent)
+	}
+
+	tree, err := ParseLayout(layout)
+	if err != nil {
+		return "", fmt.Errorf("ParseLayout: %w", err)
+	}
+	if _, ok := tree.PanePath(paneID); !ok {
+		return "", fmt.Errorf("pane %d is not in the layout", paneID)
+	}
+
+	if opts.Pinned[paneID] {
+		return "", fmt.Errorf("pane %d is pinned", paneID)
+	}
+
+	zoomed := zoomLayout(tree, paneID, opts)
+
+	switch format {
+	case "layout":
+		return BuildLayout(zoomed) + "\n", nil
+	case "json":
+		data, err := json.MarshalIndent(zoomed, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(data) + "\n", nil
+	case "diagram":
+		if width < 2 {
+			return "", fmt.Errorf("invalid width: %d", width)
+		}
+		return RenderLayout(zoomed, width, defaultRenderHeight(zoomed, width)), nil
+	default:
+		return "", fmt.Errorf("unknown format: %s", format)
+	}
+}
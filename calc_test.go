@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestCalcLayout_LayoutFormat(t *testing.T) {
+	got, err := calcLayout(cortexLayout, 41, zoomOptions{Percent: DefaultZoomPercent}, "layout", defaultRenderWidth)
+	if err != nil {
+		t.Fatalf("calcLayout failed: %v", err)
+	}
+
+	expected := "232d,255x61,0,0{165x61,0,0[165x21,0,0,26,165x39,0,22,41],44x61,166,0,36,44x61,211,0,42}\n"
+	if got != expected {
+		t.Errorf("calcLayout: got %s, want %s", got, expected)
+	}
+}
+
+func TestCalcLayout_JSONFormat(t *testing.T) {
+	got, err := calcLayout("1234,199x53,0,0{99x53,0,0,1,99x53,100,0,2}", 2, zoomOptions{Percent: 70}, "json", defaultRenderWidth)
+	if err != nil {
+		t.Fatalf("calcLayout failed: %v", err)
+	}
+
+	var root struct {
+		Type     string `json:"type"`
+		Children []struct {
+			Type  string `json:"type"`
+			Width int    `json:"width"`
+			Pane  *int   `json:"pane"`
+		} `json:"children"`
+	}
+	if err := json.Unmarshal([]byte(got), &root); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, got)
+	}
+
+	if root.Type != "horizontal" {
+		t.Errorf("root type: got %q, want horizontal", root.Type)
+	}
+	if len(root.Children) != 2 {
+		t.Fatalf("root children: got %d, want 2", len(root.Children))
+	}
+	active := root.Children[1]
+	if active.Type != "pane" || active.Pane == nil || *active.Pane != 2 {
+		t.Errorf("second child: got %+v, want pane 2", active)
+	}
+	if active.Width != 138 {
+		t.Errorf("pane 2 width: got %d, want 138", active.Width)
+	}
+}
+
+func TestCalcLayout_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		layout string
+		pane   int
+		pct    int
+		format string
+		errMsg string
+	}{
+		{"percent too high", cortexLayout, 26, 99, "layout", "percent must be"},
+		{"unknown pane", cortexLayout, 7, 65, "layout", "not in the layout"},
+		{"bad layout", "garbage", 1, 65, "layout", "ParseLayout"},
+		{"unknown format", cortexLayout, 26, 65, "yaml", "unknown format"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := calcLayout(tc.layout, tc.pane, zoomOptions{Percent: tc.pct}, tc.format, defaultRenderWidth)
+			if err == nil || !strings.Contains(err.Error(), tc.errMsg) {
+				t.Errorf("expected error containing %q, got %v", tc.errMsg, err)
+			}
+		})
+	}
+}
@@ -1,10 +1,13 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"testing"
@@ -29,6 +32,10 @@ type tmuxTest struct {
 	configDir  string
 	binary     string
 	cleanup    func()
+
+	// plainClient runs the plugin as a non-UTF-8 tmux client, as from cron or
+	// CI: TMUX unset and a C locale
+	plainClient bool
 }
 
 // newTmuxTest creates a new tmux test environment
@@ -101,13 +108,28 @@ func (tt *tmuxTest) tmuxOutput(args ...string) (string, error) {
 	return strings.TrimSpace(string(out)), nil
 }
 
-// runPlugin runs the focus-zoom binary with the test socket
-func (tt *tmuxTest) runPlugin(args ...string) error {
-	cmd := exec.Command(tt.binary, args...)
-	cmd.Env = append(os.Environ(),
+// pluginEnv returns the environment the focus-zoom binary runs with
+func (tt *tmuxTest) pluginEnv() []string {
+	var env []string
+	for _, kv := range os.Environ() {
+		if tt.plainClient && (strings.HasPrefix(kv, "TMUX=") || strings.HasPrefix(kv, "LC_") || strings.HasPrefix(kv, "LANG=")) {
+			continue
+		}
+		env = append(env, kv)
+	}
+	if tt.plainClient {
+		env = append(env, "LC_ALL=C")
+	}
+	return append(env,
 		"TMUX_SOCKET="+tt.socketPath,       // Tell binary which socket to use
 		"FOCUS_ZOOM_CONFIG_DIR="+tt.configDir, // Use isolated config
 	)
+}
+
+// runPlugin runs the focus-zoom binary with the test socket
+func (tt *tmuxTest) runPlugin(args ...string) error {
+	cmd := exec.Command(tt.binary, args...)
+	cmd.Env = tt.pluginEnv()
 	out, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("plugin %v failed: %v\n%s", args, err, out)
@@ -115,6 +137,22 @@ func (tt *tmuxTest) runPlugin(args ...string) error {
 	return nil
 }
 
+// pluginExitCode runs the focus-zoom binary and returns its exit code
+func (tt *tmuxTest) pluginExitCode(args ...string) int {
+	tt.t.Helper()
+	cmd := exec.Command(tt.binary, args...)
+	cmd.Env = tt.pluginEnv()
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	if err != nil {
+		tt.t.Fatalf("plugin %v failed: %v", args, err)
+	}
+	return 0
+}
+
 // getLayout returns the current window layout
 func (tt *tmuxTest) getLayout() (string, error) {
 	return tt.tmuxOutput("display-message", "-p", "#{window_layout}")
@@ -464,7 +502,7 @@ func TestIntegration_ToggleOffAfterPaneClose(t *testing.T) {
 
 	t.Log("Zoom enabled with 3 panes")
 
-	// Close a pane
+	// Close the zoomed pane
 	if err := tt.tmux("kill-pane", "-t", "%2"); err != nil {
 		t.Fatalf("kill-pane failed: %v", err)
 	}
@@ -473,20 +511,1108 @@ func TestIntegration_ToggleOffAfterPaneClose(t *testing.T) {
 	count, _ := tt.getPaneCount()
 	t.Logf("After close: %d panes", count)
 
-	// Toggle OFF - should not crash, should gracefully skip restore
+	// Toggle OFF - the snapshot is restored around the closed pane
 	if err := tt.runPlugin("toggle"); err != nil {
 		t.Fatalf("toggle off failed: %v", err)
 	}
+	time.Sleep(100 * time.Millisecond)
 
-	// Verify we still have 2 panes with valid layout
 	widths, err := tt.getPaneWidths()
 	if err != nil {
 		t.Fatalf("getPaneWidths failed: %v", err)
 	}
+	t.Logf("Final widths: %v", widths)
 	if len(widths) != 2 {
-		t.Errorf("expected 2 panes, got %d", len(widths))
+		t.Fatalf("expected 2 panes, got %d", len(widths))
+	}
+	// The two remaining panes were even in the snapshot and share the closed pane's space
+	if abs(widths[0]-widths[1]) > 2 {
+		t.Errorf("expected even widths after restore, got %v", widths)
+	}
+}
+
+func TestIntegration_ToggleOffRestoresAfterPaneClose(t *testing.T) {
+	tt := newTmuxTest(t)
+	defer tt.close()
+
+	// Create three even panes
+	if err := tt.splitHorizontal(); err != nil {
+		t.Fatalf("split 1 failed: %v", err)
+	}
+	if err := tt.splitHorizontal(); err != nil {
+		t.Fatalf("split 2 failed: %v", err)
+	}
+	if err := tt.evenHorizontal(); err != nil {
+		t.Fatalf("even layout failed: %v", err)
+	}
+
+	// Enable zoom on the rightmost pane
+	if err := tt.runPlugin("toggle"); err != nil {
+		t.Fatalf("toggle on failed: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	// Close the middle pane
+	if err := tt.tmux("kill-pane", "-t", "%1"); err != nil {
+		t.Fatalf("kill-pane failed: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	zoomedWidths, _ := tt.getPaneWidths()
+	t.Logf("Zoomed widths after close: %v", zoomedWidths)
+
+	// Toggle OFF - snapshot minus the closed pane should be restored
+	if err := tt.runPlugin("toggle"); err != nil {
+		t.Fatalf("toggle off failed: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	widths, err := tt.getPaneWidths()
+	if err != nil {
+		t.Fatalf("getPaneWidths failed: %v", err)
+	}
+	t.Logf("Restored widths: %v", widths)
+
+	if len(widths) != 2 {
+		t.Fatalf("expected 2 panes, got %d", len(widths))
+	}
+	// The closed pane's space is shared, so the two remaining panes are even again
+	if abs(widths[0]-widths[1]) > 2 {
+		t.Errorf("expected even widths after restore, got %v", widths)
+	}
+}
+
+func TestIntegration_ToggleOffAfterWindowResize(t *testing.T) {
+	tt := newTmuxTest(t)
+	defer tt.close()
+
+	// Create two even panes in the 200 column window
+	if err := tt.splitHorizontal(); err != nil {
+		t.Fatalf("split failed: %v", err)
+	}
+	if err := tt.evenHorizontal(); err != nil {
+		t.Fatalf("even layout failed: %v", err)
+	}
+
+	if err := tt.runPlugin("toggle"); err != nil {
+		t.Fatalf("toggle on failed: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	// Shrink the window while zoomed
+	if err := tt.tmux("resize-window", "-x", "150", "-y", "40"); err != nil {
+		t.Fatalf("resize-window failed: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	if err := tt.runPlugin("toggle"); err != nil {
+		t.Fatalf("toggle off failed: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	widths, err := tt.getPaneWidths()
+	if err != nil {
+		t.Fatalf("getPaneWidths failed: %v", err)
+	}
+	t.Logf("Restored widths after resize: %v", widths)
+
+	if len(widths) != 2 {
+		t.Fatalf("expected 2 panes, got %d", len(widths))
+	}
+	if widths[0]+widths[1]+1 != 150 {
+		t.Errorf("expected panes to fill the resized window, got %v", widths)
+	}
+	if abs(widths[0]-widths[1]) > 2 {
+		t.Errorf("expected even widths after restore, got %v", widths)
+	}
+}
+
+func TestIntegration_ToggleOffRemapsReplacedPane(t *testing.T) {
+	tt := newTmuxTest(t)
+	defer tt.close()
+
+	// Three even panes: %0 %1 %2
+	if err := tt.splitHorizontal(); err != nil {
+		t.Fatalf("split 1 failed: %v", err)
+	}
+	if err := tt.splitHorizontal(); err != nil {
+		t.Fatalf("split 2 failed: %v", err)
+	}
+	if err := tt.evenHorizontal(); err != nil {
+		t.Fatalf("even layout failed: %v", err)
+	}
+
+	if err := tt.runPlugin("toggle"); err != nil {
+		t.Fatalf("toggle on failed: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	// Replace the middle pane: close %1 and open %3 to the right of %2
+	if err := tt.tmux("kill-pane", "-t", "%1"); err != nil {
+		t.Fatalf("kill-pane failed: %v", err)
+	}
+	if err := tt.tmux("split-window", "-h", "-t", "%2"); err != nil {
+		t.Fatalf("split-window failed: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	if err := tt.runPlugin("toggle"); err != nil {
+		t.Fatalf("toggle off failed: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	// The new pane takes the closed pane's place; %2 stays on the right
+	out, err := tt.tmuxOutput("list-panes", "-F", "#{pane_left}:#{pane_id}")
+	if err != nil {
+		t.Fatalf("list-panes failed: %v", err)
+	}
+	t.Logf("Panes after restore: %s", strings.ReplaceAll(out, "\n", " "))
+
+	byLeft := make(map[int]string)
+	var lefts []int
+	for _, line := range strings.Split(out, "\n") {
+		parts := strings.SplitN(line, ":", 2)
+		left, _ := strconv.Atoi(parts[0])
+		byLeft[left] = parts[1]
+		lefts = append(lefts, left)
+	}
+	sort.Ints(lefts)
+
+	var order []string
+	for _, left := range lefts {
+		order = append(order, byLeft[left])
+	}
+	if strings.Join(order, " ") != "%0 %3 %2" {
+		t.Errorf("expected panes left to right %%0 %%3 %%2, got %v", order)
+	}
+}
+
+func TestIntegration_RefreshAfterSplit(t *testing.T) {
+	tt := newTmuxTest(t)
+	defer tt.close()
+
+	if err := tt.splitHorizontal(); err != nil {
+		t.Fatalf("split failed: %v", err)
+	}
+	if err := tt.evenHorizontal(); err != nil {
+		t.Fatalf("even layout failed: %v", err)
+	}
+
+	if err := tt.runPlugin("toggle"); err != nil {
+		t.Fatalf("toggle on failed: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	// Splitting makes the new pane active without a select-pane
+	if err := tt.splitHorizontal(); err != nil {
+		t.Fatalf("split 2 failed: %v", err)
+	}
+	if err := tt.runPlugin("refresh"); err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	widths, err := tt.getPaneWidths()
+	if err != nil {
+		t.Fatalf("getPaneWidths failed: %v", err)
+	}
+	t.Logf("Widths after split and refresh: %v", widths)
+
+	if len(widths) != 3 {
+		t.Fatalf("expected 3 panes, got %d", len(widths))
+	}
+	// The new pane (last) should be the zoomed one
+	if widths[2] <= widths[0] || widths[2] <= widths[1] {
+		t.Errorf("expected new pane to be largest, got %v", widths)
+	}
+}
+
+func TestIntegration_PinnedPaneKeepsSize(t *testing.T) {
+	t.Run("utf8", func(t *testing.T) { testPinnedPaneKeepsSize(t, false) })
+	t.Run("non-utf8", func(t *testing.T) { testPinnedPaneKeepsSize(t, true) })
+}
+
+func testPinnedPaneKeepsSize(t *testing.T, plainClient bool) {
+	tt := newTmuxTest(t)
+	defer tt.close()
+	tt.plainClient = plainClient
+
+	// Three even panes, the rightmost pinned
+	if err := tt.splitHorizontal(); err != nil {
+		t.Fatalf("split 1 failed: %v", err)
+	}
+	if err := tt.splitHorizontal(); err != nil {
+		t.Fatalf("split 2 failed: %v", err)
+	}
+	if err := tt.evenHorizontal(); err != nil {
+		t.Fatalf("even layout failed: %v", err)
+	}
+	if err := tt.tmux("set-option", "-p", "-t", "%2", "@focus-zoom-pinned", "on"); err != nil {
+		t.Fatalf("set-option failed: %v", err)
+	}
+
+	initialWidths, _ := tt.getPaneWidths()
+
+	// Focusing the pinned pane doesn't zoom
+	if err := tt.runPlugin("toggle"); err != nil {
+		t.Fatalf("toggle on failed: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	widths, _ := tt.getPaneWidths()
+	t.Logf("Widths with pinned pane focused: %v", widths)
+	for i := range widths {
+		if widths[i] != initialWidths[i] {
+			t.Errorf("expected no zoom while the pinned pane is focused, got %v (was %v)", widths, initialWidths)
+			break
+		}
+	}
+
+	// Focusing another pane zooms it, but the pinned pane keeps its width
+	if err := tt.selectPane(0); err != nil {
+		t.Fatalf("select pane failed: %v", err)
+	}
+	if err := tt.runPlugin("apply"); err != nil {
+		t.Fatalf("apply failed: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	widths, _ = tt.getPaneWidths()
+	t.Logf("Widths with pane 0 focused: %v", widths)
+	if widths[2] != initialWidths[2] {
+		t.Errorf("pinned pane width changed: got %d, want %d", widths[2], initialWidths[2])
+	}
+	if widths[0] <= widths[1] {
+		t.Errorf("expected pane 0 to grow, got %v", widths)
+	}
+}
+
+func TestIntegration_PerPaneZoomPercent(t *testing.T) {
+	tt := newTmuxTest(t)
+	defer tt.close()
+
+	if err := tt.splitHorizontal(); err != nil {
+		t.Fatalf("split failed: %v", err)
+	}
+	if err := tt.evenHorizontal(); err != nil {
+		t.Fatalf("even layout failed: %v", err)
+	}
+	// Pane 1 zooms to 80%, pane 0 uses the default
+	if err := tt.tmux("set-option", "-p", "-t", "%1", "@focus-zoom-percent", "80"); err != nil {
+		t.Fatalf("set-option failed: %v", err)
+	}
+
+	if err := tt.runPlugin("toggle"); err != nil {
+		t.Fatalf("toggle on failed: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	widths, _ := tt.getPaneWidths()
+	t.Logf("Widths with pane 1 focused: %v", widths)
+	if want := (199 * 80) / 100; abs(widths[1]-want) > 1 {
+		t.Errorf("pane 1 width: got %d, want ~%d (80%%)", widths[1], want)
+	}
+
+	if err := tt.selectPane(0); err != nil {
+		t.Fatalf("select pane failed: %v", err)
+	}
+	if err := tt.runPlugin("apply"); err != nil {
+		t.Fatalf("apply failed: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	widths, _ = tt.getPaneWidths()
+	t.Logf("Widths with pane 0 focused: %v", widths)
+	if want := (199 * DefaultZoomPercent) / 100; abs(widths[0]-want) > 1 {
+		t.Errorf("pane 0 width: got %d, want ~%d (default)", widths[0], want)
+	}
+}
+
+func TestIntegration_AutoEnable(t *testing.T) {
+	tt := newTmuxTest(t)
+	defer tt.close()
+
+	if err := tt.tmux("set-option", "-g", "@focus-zoom-auto", "on"); err != nil {
+		t.Fatalf("set auto failed: %v", err)
+	}
+	if err := tt.tmux("set-option", "-g", "@focus-zoom-auto-min-panes", "3"); err != nil {
+		t.Fatalf("set min panes failed: %v", err)
+	}
+
+	// Two panes: below the minimum, nothing happens
+	if err := tt.splitHorizontal(); err != nil {
+		t.Fatalf("split failed: %v", err)
+	}
+	if err := tt.evenHorizontal(); err != nil {
+		t.Fatalf("even layout failed: %v", err)
+	}
+	if err := tt.runPlugin("refresh"); err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+	widths, err := tt.getPaneWidths()
+	if err != nil {
+		t.Fatalf("getPaneWidths failed: %v", err)
+	}
+	if abs(widths[0]-widths[1]) > 1 {
+		t.Errorf("expected even widths below min panes, got %v", widths)
+	}
+
+	// The third pane enables zoom
+	if err := tt.splitHorizontal(); err != nil {
+		t.Fatalf("split 2 failed: %v", err)
+	}
+	if err := tt.evenHorizontal(); err != nil {
+		t.Fatalf("even layout failed: %v", err)
+	}
+	evenLayout, _ := tt.getLayout()
+	if err := tt.runPlugin("refresh"); err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+	widths, err = tt.getPaneWidths()
+	if err != nil {
+		t.Fatalf("getPaneWidths failed: %v", err)
+	}
+	t.Logf("Widths after auto-enable: %v", widths)
+	if widths[2] <= widths[0] || widths[2] <= widths[1] {
+		t.Errorf("expected active pane to be zoomed, got %v", widths)
+	}
+
+	// Toggling off restores the layout and keeps the window off
+	if err := tt.runPlugin("toggle"); err != nil {
+		t.Fatalf("toggle off failed: %v", err)
+	}
+	if err := tt.runPlugin("refresh"); err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+	layout, _ := tt.getLayout()
+	if layout != evenLayout {
+		t.Errorf("expected layout %s after toggle off, got %s", evenLayout, layout)
+	}
+}
+
+func TestIntegration_GlobalToggle(t *testing.T) {
+	tt := newTmuxTest(t)
+	defer tt.close()
+
+	if err := tt.splitHorizontal(); err != nil {
+		t.Fatalf("split failed: %v", err)
+	}
+	if err := tt.evenHorizontal(); err != nil {
+		t.Fatalf("even layout failed: %v", err)
+	}
+	firstWindow, _ := tt.tmuxOutput("display-message", "-p", "#{window_id}")
+	firstLayout, _ := tt.getLayout()
+
+	if err := tt.runPlugin("toggle", "--global"); err != nil {
+		t.Fatalf("toggle --global failed: %v", err)
+	}
+	widths, err := tt.getPaneWidths()
+	if err != nil {
+		t.Fatalf("getPaneWidths failed: %v", err)
+	}
+	if abs(widths[0]-widths[1]) <= 1 {
+		t.Errorf("expected first window zoomed, got %v", widths)
+	}
+
+	// A new window is zoomed as soon as it has panes to zoom
+	if err := tt.tmux("new-window"); err != nil {
+		t.Fatalf("new-window failed: %v", err)
+	}
+	if err := tt.splitHorizontal(); err != nil {
+		t.Fatalf("split failed: %v", err)
+	}
+	if err := tt.evenHorizontal(); err != nil {
+		t.Fatalf("even layout failed: %v", err)
+	}
+	secondWindow, _ := tt.tmuxOutput("display-message", "-p", "#{window_id}")
+	secondLayout, _ := tt.getLayout()
+	if err := tt.runPlugin("refresh"); err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+	widths, err = tt.getPaneWidths()
+	if err != nil {
+		t.Fatalf("getPaneWidths failed: %v", err)
+	}
+	if abs(widths[0]-widths[1]) <= 1 {
+		t.Errorf("expected second window zoomed, got %v", widths)
+	}
+
+	// Toggling off restores both windows without leaving the current one
+	if err := tt.runPlugin("toggle", "--global"); err != nil {
+		t.Fatalf("toggle --global off failed: %v", err)
+	}
+	for window, want := range map[string]string{firstWindow: firstLayout, secondWindow: secondLayout} {
+		layout, _ := tt.tmuxOutput("display-message", "-p", "-t", window, "#{window_layout}")
+		if layout != want {
+			t.Errorf("window %s: expected layout %s, got %s", window, want, layout)
+		}
+	}
+	current, _ := tt.tmuxOutput("display-message", "-p", "#{window_id}")
+	if current != secondWindow {
+		t.Errorf("expected to stay in window %s, got %s", secondWindow, current)
+	}
+}
+
+func TestIntegration_EnableDisableExitCodes(t *testing.T) {
+	tt := newTmuxTest(t)
+	defer tt.close()
+
+	if err := tt.splitHorizontal(); err != nil {
+		t.Fatalf("split failed: %v", err)
+	}
+	if err := tt.evenHorizontal(); err != nil {
+		t.Fatalf("even layout failed: %v", err)
+	}
+
+	steps := []struct {
+		args     []string
+		expected int
+	}{
+		{[]string{"disable"}, 2},
+		{[]string{"enable"}, 0},
+		{[]string{"enable"}, 2},
+		{[]string{"set-percent", "80"}, 0},
+		{[]string{"set-percent", "80"}, 2},
+		{[]string{"set-percent", "200"}, 1},
+		{[]string{"reset"}, 0},
+		{[]string{"reset"}, 2},
+		{[]string{"disable"}, 0},
+		{[]string{"disable"}, 2},
+		{[]string{"enable", "--global"}, 0},
+		{[]string{"enable", "--global"}, 2},
+		{[]string{"disable", "--global"}, 0},
+		{[]string{"disable", "--global"}, 2},
+	}
+	for _, step := range steps {
+		if got := tt.pluginExitCode(step.args...); got != step.expected {
+			t.Errorf("%v: exit code %d, want %d", step.args, got, step.expected)
+		}
+	}
+
+	widths, err := tt.getPaneWidths()
+	if err != nil {
+		t.Fatalf("getPaneWidths failed: %v", err)
+	}
+	if abs(widths[0]-widths[1]) > 1 {
+		t.Errorf("expected even widths after disable, got %v", widths)
+	}
+}
+
+func TestIntegration_SetPercent(t *testing.T) {
+	tt := newTmuxTest(t)
+	defer tt.close()
+
+	if err := tt.splitHorizontal(); err != nil {
+		t.Fatalf("split failed: %v", err)
+	}
+	if err := tt.runPlugin("enable"); err != nil {
+		t.Fatalf("enable failed: %v", err)
+	}
+	if err := tt.runPlugin("set-percent", "80"); err != nil {
+		t.Fatalf("set-percent failed: %v", err)
+	}
+
+	value, _ := tt.tmuxOutput("show-option", "-wqv", "@focus-zoom-percent")
+	if value != "80" {
+		t.Errorf("expected window option 80, got %q", value)
+	}
+	widths, err := tt.getPaneWidths()
+	if err != nil {
+		t.Fatalf("getPaneWidths failed: %v", err)
+	}
+	// 80% of 199 usable columns
+	if abs(widths[1]-159) > 1 {
+		t.Errorf("expected active pane ~159 wide at 80%%, got %v", widths)
+	}
+}
+
+func TestIntegration_GrowShrink(t *testing.T) {
+	tt := newTmuxTest(t)
+	defer tt.close()
+
+	if err := tt.splitHorizontal(); err != nil {
+		t.Fatalf("split failed: %v", err)
+	}
+	if err := tt.runPlugin("enable"); err != nil {
+		t.Fatalf("enable failed: %v", err)
+	}
+	before, err := tt.getPaneWidths()
+	if err != nil {
+		t.Fatalf("getPaneWidths failed: %v", err)
+	}
+
+	if err := tt.runPlugin("grow"); err != nil {
+		t.Fatalf("grow failed: %v", err)
+	}
+	value, _ := tt.tmuxOutput("show-option", "-wqv", "@focus-zoom-percent")
+	if value != "70" {
+		t.Errorf("expected window percent 70 after grow, got %q", value)
+	}
+	after, err := tt.getPaneWidths()
+	if err != nil {
+		t.Fatalf("getPaneWidths failed: %v", err)
+	}
+	if after[1] <= before[1] {
+		t.Errorf("expected active pane to grow: before %v, after %v", before, after)
+	}
+
+	// Shrinking stops at the minimum
+	if err := tt.tmux("set-option", "-w", "@focus-zoom-percent", "12"); err != nil {
+		t.Fatalf("set percent failed: %v", err)
+	}
+	if got := tt.pluginExitCode("shrink"); got != 0 {
+		t.Errorf("shrink to minimum: exit code %d, want 0", got)
+	}
+	if got := tt.pluginExitCode("shrink"); got != 0 {
+		t.Errorf("shrink at minimum: exit code %d, want 0", got)
+	}
+	value, _ = tt.tmuxOutput("show-option", "-wqv", "@focus-zoom-percent")
+	if value != "10" {
+		t.Errorf("expected window percent 10, got %q", value)
+	}
+
+	// A pane-scope percent doesn't stop grow stepping the window's
+	if err := tt.tmux("set-option", "-p", "@focus-zoom-percent", "50"); err != nil {
+		t.Fatalf("set pane percent failed: %v", err)
+	}
+	for _, want := range []string{"15", "20"} {
+		if got := tt.pluginExitCode("grow"); got != 0 {
+			t.Errorf("grow with a pane percent: exit code %d, want 0", got)
+		}
+		value, _ = tt.tmuxOutput("show-option", "-wqv", "@focus-zoom-percent")
+		if value != want {
+			t.Errorf("expected window percent %s, got %q", want, value)
+		}
+	}
+}
+
+func TestIntegration_CyclePresets(t *testing.T) {
+	tt := newTmuxTest(t)
+	defer tt.close()
+
+	if err := tt.splitHorizontal(); err != nil {
+		t.Fatalf("split failed: %v", err)
+	}
+	if err := tt.evenHorizontal(); err != nil {
+		t.Fatalf("even layout failed: %v", err)
+	}
+	evenLayout, _ := tt.getLayout()
+	if err := tt.tmux("set-option", "-g", "@focus-zoom-presets", "70,90,off"); err != nil {
+		t.Fatalf("set presets failed: %v", err)
+	}
+
+	// Off -> 70 -> 90 -> off
+	for _, want := range []int{139, 179} {
+		if err := tt.runPlugin("cycle"); err != nil {
+			t.Fatalf("cycle failed: %v", err)
+		}
+		widths, err := tt.getPaneWidths()
+		if err != nil {
+			t.Fatalf("getPaneWidths failed: %v", err)
+		}
+		if abs(widths[1]-want) > 1 {
+			t.Errorf("expected active pane ~%d wide, got %v", want, widths)
+		}
+	}
+
+	if err := tt.runPlugin("cycle"); err != nil {
+		t.Fatalf("cycle failed: %v", err)
+	}
+	layout, _ := tt.getLayout()
+	if layout != evenLayout {
+		t.Errorf("expected layout %s after cycling off, got %s", evenLayout, layout)
+	}
+
+	// With the default presets, status shows the preset cycled to
+	if err := tt.tmux("set-option", "-gu", "@focus-zoom-presets"); err != nil {
+		t.Fatalf("unset presets failed: %v", err)
+	}
+	if err := tt.runPlugin("cycle"); err != nil {
+		t.Fatalf("cycle failed: %v", err)
+	}
+	cmd := exec.Command(tt.binary, "status", "--format", "{state}")
+	cmd.Env = tt.pluginEnv()
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("status failed: %v", err)
+	}
+	if got := strings.TrimSpace(string(out)); got != "50%" {
+		t.Errorf("status after cycling to the first default preset: got %q, want 50%%", got)
+	}
+}
+
+func TestIntegration_MaxMode(t *testing.T) {
+	tt := newTmuxTest(t)
+	defer tt.close()
+
+	if err := tt.splitHorizontal(); err != nil {
+		t.Fatalf("split failed: %v", err)
+	}
+	if got := tt.pluginExitCode("max"); got != 0 {
+		t.Errorf("max with zoom off: exit code %d, want 0", got)
+	}
+	if err := tt.runPlugin("enable"); err != nil {
+		t.Fatalf("enable failed: %v", err)
+	}
+	zoomed, err := tt.getPaneWidths()
+	if err != nil {
+		t.Fatalf("getPaneWidths failed: %v", err)
+	}
+
+	if err := tt.runPlugin("max"); err != nil {
+		t.Fatalf("max failed: %v", err)
+	}
+	widths, err := tt.getPaneWidths()
+	if err != nil {
+		t.Fatalf("getPaneWidths failed: %v", err)
+	}
+	t.Logf("Widths in max mode: %v", widths)
+	// 95% of 199 usable columns, with the other pane still visible
+	if abs(widths[1]-189) > 1 || widths[0] < 1 {
+		t.Errorf("expected active pane ~189 wide, got %v", widths)
+	}
+
+	// Refresh keeps max mode
+	if err := tt.runPlugin("refresh"); err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+	after, _ := tt.getPaneWidths()
+	if after[1] != widths[1] {
+		t.Errorf("expected refresh to keep max mode, got %v", after)
+	}
+
+	// Pressing again returns to the regular percentage
+	if err := tt.runPlugin("max"); err != nil {
+		t.Fatalf("max 2 failed: %v", err)
+	}
+	after, _ = tt.getPaneWidths()
+	if after[1] != zoomed[1] {
+		t.Errorf("expected regular zoom %v after second press, got %v", zoomed, after)
+	}
+
+	// A focus change ends max mode
+	if err := tt.runPlugin("max"); err != nil {
+		t.Fatalf("max 3 failed: %v", err)
+	}
+	if err := tt.selectPane(0); err != nil {
+		t.Fatalf("select pane failed: %v", err)
+	}
+	if err := tt.runPlugin("apply"); err != nil {
+		t.Fatalf("apply failed: %v", err)
+	}
+	after, _ = tt.getPaneWidths()
+	if after[0] != zoomed[1] {
+		t.Errorf("expected first pane at regular zoom %d after focus change, got %v", zoomed[1], after)
+	}
+	if value, _ := tt.tmuxOutput("show-option", "-wqv", "@focus-zoom-max"); value != "" {
+		t.Errorf("expected @focus-zoom-max cleared, got %q", value)
+	}
+
+	// Turning zoom off ends max mode too, since apply does nothing while it's off
+	if err := tt.runPlugin("max"); err != nil {
+		t.Fatalf("max 4 failed: %v", err)
+	}
+	if err := tt.runPlugin("disable"); err != nil {
+		t.Fatalf("disable failed: %v", err)
+	}
+	if value, _ := tt.tmuxOutput("show-option", "-wqv", "@focus-zoom-max"); value != "" {
+		t.Errorf("expected @focus-zoom-max cleared by disable, got %q", value)
+	}
+}
+
+func TestIntegration_NativeZoom(t *testing.T) {
+	tt := newTmuxTest(t)
+	defer tt.close()
+
+	if err := tt.splitHorizontal(); err != nil {
+		t.Fatalf("split failed: %v", err)
+	}
+	if err := tt.evenHorizontal(); err != nil {
+		t.Fatalf("even layout failed: %v", err)
+	}
+	evenLayout, _ := tt.getLayout()
+
+	// Enabling while natively zoomed leaves the zoom alone
+	if err := tt.tmux("resize-pane", "-Z"); err != nil {
+		t.Fatalf("native zoom failed: %v", err)
+	}
+	if err := tt.runPlugin("enable"); err != nil {
+		t.Fatalf("enable failed: %v", err)
+	}
+	if flag, _ := tt.tmuxOutput("display-message", "-p", "#{window_zoomed_flag}"); flag != "1" {
+		t.Errorf("expected window to stay natively zoomed, got flag %q", flag)
+	}
+
+	// Unzooming runs the deferred zoom
+	if err := tt.tmux("resize-pane", "-Z"); err != nil {
+		t.Fatalf("native unzoom failed: %v", err)
+	}
+	if err := tt.runPlugin("refresh", "--deferred"); err != nil {
+		t.Fatalf("refresh --deferred failed: %v", err)
+	}
+	widths, err := tt.getPaneWidths()
+	if err != nil {
+		t.Fatalf("getPaneWidths failed: %v", err)
+	}
+	if widths[1] <= widths[0] {
+		t.Errorf("expected deferred zoom applied, got %v", widths)
+	}
+
+	// Without a deferred zoom, manual resizes are kept
+	if err := tt.tmux("resize-pane", "-L", "5"); err != nil {
+		t.Fatalf("resize failed: %v", err)
+	}
+	resized, _ := tt.getPaneWidths()
+	if err := tt.runPlugin("refresh", "--deferred"); err != nil {
+		t.Fatalf("refresh --deferred failed: %v", err)
+	}
+	after, _ := tt.getPaneWidths()
+	if after[1] != resized[1] {
+		t.Errorf("expected manual resize %v kept, got %v", resized, after)
+	}
+
+	// Toggling off while natively zoomed restores underneath and stays zoomed
+	if err := tt.tmux("resize-pane", "-Z"); err != nil {
+		t.Fatalf("native zoom failed: %v", err)
+	}
+	if err := tt.runPlugin("disable"); err != nil {
+		t.Fatalf("disable failed: %v", err)
+	}
+	if flag, _ := tt.tmuxOutput("display-message", "-p", "#{window_zoomed_flag}"); flag != "1" {
+		t.Errorf("expected window natively zoomed after disable, got flag %q", flag)
+	}
+	if err := tt.tmux("resize-pane", "-Z"); err != nil {
+		t.Fatalf("native unzoom failed: %v", err)
+	}
+	layout, _ := tt.getLayout()
+	if layout != evenLayout {
+		t.Errorf("expected layout %s after disable, got %s", evenLayout, layout)
+	}
+}
+
+func TestIntegration_StatusJSON(t *testing.T) {
+	tt := newTmuxTest(t)
+	defer tt.close()
+
+	if err := tt.splitHorizontal(); err != nil {
+		t.Fatalf("split failed: %v", err)
+	}
+	if err := tt.runPlugin("enable"); err != nil {
+		t.Fatalf("enable failed: %v", err)
+	}
+
+	cmd := exec.Command(tt.binary, "status", "--json")
+	cmd.Env = tt.pluginEnv()
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("status --json failed: %v", err)
+	}
+
+	var status statusJSON
+	if err := json.Unmarshal(out, &status); err != nil {
+		t.Fatalf("invalid JSON %s: %v", out, err)
+	}
+	activePane, _ := tt.tmuxOutput("display-message", "-p", "#{pane_id}")
+	windowID, _ := tt.tmuxOutput("display-message", "-p", "#{window_id}")
+
+	if !status.Enabled || status.Mode != "manual" {
+		t.Errorf("expected enabled in manual mode, got %+v", status)
+	}
+	if status.WindowID != windowID || status.ActivePane != activePane {
+		t.Errorf("expected window %s pane %s, got %+v", windowID, activePane, status)
+	}
+	if status.Percent != 65 || status.SnapshotPanes != 2 || !status.CanRestore {
+		t.Errorf("expected 65%%, 2 snapshot panes and restorable, got %+v", status)
+	}
+}
+
+func TestIntegration_StatusForWindow(t *testing.T) {
+	tt := newTmuxTest(t)
+	defer tt.close()
+
+	if err := tt.splitHorizontal(); err != nil {
+		t.Fatalf("split failed: %v", err)
+	}
+	if err := tt.runPlugin("enable"); err != nil {
+		t.Fatalf("enable failed: %v", err)
+	}
+	sessionID, _ := tt.tmuxOutput("display-message", "-p", "#{session_id}")
+	zoomedWindow, _ := tt.tmuxOutput("display-message", "-p", "#{window_id}")
+
+	// The current window moves on, but the zoomed window's status line still reports ON
+	if err := tt.tmux("new-window"); err != nil {
+		t.Fatalf("new-window failed: %v", err)
+	}
+	otherWindow, _ := tt.tmuxOutput("display-message", "-p", "#{window_id}")
+
+	status := func(args ...string) string {
+		cmd := exec.Command(tt.binary, append([]string{"status", "--format", "{state}"}, args...)...)
+		cmd.Env = tt.pluginEnv()
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("status %v failed: %v", args, err)
+		}
+		return strings.TrimSpace(string(out))
+	}
+
+	if got := status(sessionID, zoomedWindow); got != "ON" {
+		t.Errorf("zoomed window %s: got %q, want ON", zoomedWindow, got)
+	}
+	if got := status(sessionID, otherWindow); got != "OFF" {
+		t.Errorf("other window %s: got %q, want OFF", otherWindow, got)
+	}
+	if got := status(); got != "OFF" {
+		t.Errorf("current window: got %q, want OFF", got)
+	}
+}
+
+func TestIntegration_StatusOptions(t *testing.T) {
+	tt := newTmuxTest(t)
+	defer tt.close()
+
+	if err := tt.splitHorizontal(); err != nil {
+		t.Fatalf("split failed: %v", err)
+	}
+	// Values with spaces, separators and format characters pass through as set
+	options := map[string]string{
+		"@focus-zoom-status-format":    "zoom | {state} {percent}%",
+		"@focus-zoom-status-style-on":  "fg=#a6e3a1,bold",
+		"@focus-zoom-status-style-off": "fg=colour244",
+	}
+	for name, value := range options {
+		if err := tt.tmux("set-option", "-g", name, value); err != nil {
+			t.Fatalf("set %s failed: %v", name, err)
+		}
+	}
+
+	status := func() string {
+		cmd := exec.Command(tt.binary, "status")
+		cmd.Env = tt.pluginEnv()
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("status failed: %v", err)
+		}
+		return string(out)
+	}
+
+	if got, want := status(), "#[fg=colour244]zoom | OFF 65%#[default] "; got != want {
+		t.Errorf("off: got %q, want %q", got, want)
+	}
+	if err := tt.runPlugin("enable"); err != nil {
+		t.Fatalf("enable failed: %v", err)
+	}
+	if got, want := status(), "#[fg=#a6e3a1,bold]zoom | ON 65%#[default] "; got != want {
+		t.Errorf("on: got %q, want %q", got, want)
+	}
+}
+
+func TestIntegration_PublishedOptions(t *testing.T) {
+	tt := newTmuxTest(t)
+	defer tt.close()
+
+	if err := tt.splitHorizontal(); err != nil {
+		t.Fatalf("split failed: %v", err)
+	}
+	published := func() string {
+		out, err := tt.tmuxOutput("display-message", "-p", "#{?@focus-zoom-active,ON,OFF} #{@focus-zoom-effective-percent}")
+		if err != nil {
+			t.Fatalf("display-message failed: %v", err)
+		}
+		return out
+	}
+
+	steps := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"enable"}, "ON 65"},
+		{[]string{"set-percent", "80"}, "ON 80"},
+		{[]string{"disable"}, "OFF"},
+		{[]string{"enable", "--global"}, "ON 80"},
+		{[]string{"disable"}, "OFF"},
+		{[]string{"enable"}, "ON 80"},
+		{[]string{"disable", "--global"}, "OFF"},
+	}
+	for _, step := range steps {
+		if err := tt.runPlugin(step.args...); err != nil {
+			t.Fatalf("%v failed: %v", step.args, err)
+		}
+		if got := published(); got != step.expected {
+			t.Errorf("after %v: got %q, want %q", step.args, got, step.expected)
+		}
+	}
+
+	// In global mode, a window toggled back on by hand stays published as on
+	// after another window is toggled on by hand
+	firstWindow, _ := tt.tmuxOutput("display-message", "-p", "#{window_id}")
+	if err := tt.runPlugin("enable", "--global"); err != nil {
+		t.Fatalf("enable --global failed: %v", err)
+	}
+	if err := tt.tmux("new-window"); err != nil {
+		t.Fatalf("new-window failed: %v", err)
+	}
+	if err := tt.splitHorizontal(); err != nil {
+		t.Fatalf("split failed: %v", err)
+	}
+	for _, window := range []string{firstWindow, ""} {
+		if window != "" {
+			if err := tt.tmux("select-window", "-t", window); err != nil {
+				t.Fatalf("select-window failed: %v", err)
+			}
+		} else if err := tt.tmux("last-window"); err != nil {
+			t.Fatalf("last-window failed: %v", err)
+		}
+		for _, args := range [][]string{{"disable"}, {"enable"}} {
+			if err := tt.runPlugin(args...); err != nil {
+				t.Fatalf("%v failed: %v", args, err)
+			}
+		}
+	}
+	active, _ := tt.tmuxOutput("display-message", "-p", "-t", firstWindow, "#{@focus-zoom-active}")
+	if active != "1" {
+		t.Errorf("first window: got @focus-zoom-active %q, want 1", active)
+	}
+}
+
+func TestIntegration_Bookmarks(t *testing.T) {
+	tt := newTmuxTest(t)
+	defer tt.close()
+
+	if err := tt.splitHorizontal(); err != nil {
+		t.Fatalf("split failed: %v", err)
+	}
+	if err := tt.evenHorizontal(); err != nil {
+		t.Fatalf("even layout failed: %v", err)
+	}
+	evenLayout, _ := tt.getLayout()
+	if err := tt.runPlugin("save", "even"); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+
+	// The bookmark follows the window through a rename and renumber
+	if err := tt.tmux("resize-pane", "-t", "%0", "-x", "150"); err != nil {
+		t.Fatalf("resize failed: %v", err)
+	}
+	if err := tt.tmux("rename-window", "renamed"); err != nil {
+		t.Fatalf("rename-window failed: %v", err)
+	}
+	if err := tt.tmux("move-window", "-t", testSession+":5"); err != nil {
+		t.Fatalf("move-window failed: %v", err)
+	}
+	if code := tt.pluginExitCode("restore", "even"); code != 0 {
+		t.Errorf("restore: exit code %d, want 0", code)
+	}
+	if layout, _ := tt.getLayout(); layout != evenLayout {
+		t.Errorf("expected layout %s after restore, got %s", evenLayout, layout)
+	}
+
+	if code := tt.pluginExitCode("restore", "missing"); code != 1 {
+		t.Errorf("restore of an unknown name: exit code %d, want 1", code)
+	}
+
+	// A pane added since saving is placed next to its neighbour
+	if err := tt.splitHorizontal(); err != nil {
+		t.Fatalf("split 2 failed: %v", err)
+	}
+	if code := tt.pluginExitCode("restore", "even"); code != 0 {
+		t.Errorf("restore with an added pane: exit code %d, want 0", code)
+	}
+	if count, _ := tt.getPaneCount(); count != 3 {
+		t.Errorf("expected 3 panes after restore, got %d", count)
+	}
+
+	// Once none of the saved panes are left the layout is kept
+	for _, pane := range []string{"%0", "%1"} {
+		if err := tt.tmux("kill-pane", "-t", pane); err != nil {
+			t.Fatalf("kill-pane %s failed: %v", pane, err)
+		}
+	}
+	if code := tt.pluginExitCode("restore", "even"); code != 1 {
+		t.Errorf("restore with no saved panes left: exit code %d, want 1", code)
+	}
+}
+
+func TestIntegration_LayoutImport(t *testing.T) {
+	tt := newTmuxTest(t)
+	defer tt.close()
+
+	if err := tt.splitHorizontal(); err != nil {
+		t.Fatalf("split failed: %v", err)
+	}
+	height, err := tt.tmuxOutput("display-message", "-p", "#{window_height}")
+	if err != nil {
+		t.Fatalf("display-message failed: %v", err)
+	}
+
+	// The panes swap sides: each goes where its ID is in the layout
+	file := filepath.Join(t.TempDir(), "layout.json")
+	writeLayout := func(left, right int) {
+		data := fmt.Sprintf(`{"type":"horizontal","width":200,"height":%[1]s,"x":0,"y":0,"children":[
+			{"type":"pane","width":49,"height":%[1]s,"x":0,"y":0,"pane":%[2]d},
+			{"type":"pane","width":150,"height":%[1]s,"x":0,"y":0,"pane":%[3]d}]}`, height, left, right)
+		if err := os.WriteFile(file, []byte(data), 0o644); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+	}
+	writeLayout(1, 0)
+	if err := tt.runPlugin("layout", "import", "--file", file); err != nil {
+		t.Fatalf("layout import failed: %v", err)
+	}
+	panes, _ := tt.tmuxOutput("list-panes", "-F", "#{pane_id}:#{pane_width}")
+	if want := "%1:49\n%0:150"; panes != want {
+		t.Errorf("panes after import: got %q, want %q", panes, want)
+	}
+
+	// A layout for other panes is rejected and leaves the window alone
+	writeLayout(0, 5)
+	if code := tt.pluginExitCode("layout", "import", "--file", file); code != 1 {
+		t.Errorf("import with a pane not in the window: exit code %d, want 1", code)
+	}
+	if after, _ := tt.tmuxOutput("list-panes", "-F", "#{pane_id}:#{pane_width}"); after != panes {
+		t.Errorf("panes after rejected import: got %q, want %q", after, panes)
+	}
+}
+
+func TestIntegration_NonUTF8Client(t *testing.T) {
+	tt := newTmuxTest(t)
+	defer tt.close()
+	tt.plainClient = true
+
+	if err := tt.splitHorizontal(); err != nil {
+		t.Fatalf("split failed: %v", err)
+	}
+	if err := tt.evenHorizontal(); err != nil {
+		t.Fatalf("even layout failed: %v", err)
+	}
+	evenLayout, _ := tt.getLayout()
+
+	if err := tt.runPlugin("toggle"); err != nil {
+		t.Fatalf("toggle failed: %v", err)
+	}
+	widths, err := tt.getPaneWidths()
+	if err != nil {
+		t.Fatalf("getPaneWidths failed: %v", err)
+	}
+	if widths[1] <= widths[0] {
+		t.Errorf("expected pane 1 to be larger after zoom, got %v", widths)
+	}
+
+	cmd := exec.Command(tt.binary, "status", "--format", "{state}")
+	cmd.Env = tt.pluginEnv()
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("status failed: %v", err)
+	}
+	if got := strings.TrimSpace(string(out)); got != "ON" {
+		t.Errorf("status: got %q, want ON", got)
+	}
+
+	if err := tt.runPlugin("toggle"); err != nil {
+		t.Fatalf("toggle off failed: %v", err)
+	}
+	if layout, _ := tt.getLayout(); layout != evenLayout {
+		t.Errorf("expected layout %s after toggle off, got %s", evenLayout, layout)
 	}
-	t.Logf("Final widths: %v", widths)
 }
 
 func abs(x int) int {
@@ -10,6 +10,10 @@ import (
 const (
 	// DefaultZoomPercent is the default percentage of window size the focused pane should occupy
 	DefaultZoomPercent = 65
+
+	// MinZoomPercent and MaxZoomPercent bound the accepted zoom percentage
+	MinZoomPercent = 10
+	MaxZoomPercent = 95
 )
 
 // SplitType represents how a layout node is split
@@ -224,64 +228,148 @@ func calculateChecksum(s string) string {
 	return fmt.Sprintf("%04x", csum)
 }
 
+// zoomOptions controls how a layout is zoomed
+type zoomOptions struct {
+	Percent int          // share of the space the active pane gets
+	Pinned  map[int]bool // panes whose size never changes
+	Axis    zoomAxis     // which splits to zoom
+}
+
 // ApplyZoomToLayout modifies a layout tree so the pane with activePaneID
 // gets zoomPercent of the available space, while others shrink proportionally.
 // Returns a new layout tree (does not modify the input).
 func ApplyZoomToLayout(node *LayoutNode, activePaneID int, zoomPercent int) *LayoutNode {
+	return applyZoomToLayout(node, activePaneID, zoomOptions{Percent: zoomPercent})
+}
+
+// applyZoomToLayout is ApplyZoomToLayout with pinned panes kept at their size
+func applyZoomToLayout(node *LayoutNode, activePaneID int, opts zoomOptions) *LayoutNode {
 	// Deep copy the tree
 	result := copyLayoutNode(node)
 
 	// Find which child contains the active pane
-	activeChildIdx := -1
-	for i, child := range result.Children {
-		if containsPane(child, activePaneID) {
-			activeChildIdx = i
-			break
-		}
-	}
-
-	if activeChildIdx == -1 {
+	path, ok := result.PanePath(activePaneID)
+	if !ok || len(path) == 0 {
 		// Active pane not found in children - return unchanged
 		return result
 	}
 
-	// Apply zoom based on split type
-	if result.SplitType == SplitHorizontal {
-		applyHorizontalZoom(result, activeChildIdx, zoomPercent)
-	} else if result.SplitType == SplitVertical {
-		applyVerticalZoom(result, activeChildIdx, zoomPercent)
+	zoomSplit(result, path[0], opts)
+	return result
+}
+
+// zoomLayout returns a copy of the tree zoomed on activePaneID at every level,
+// the same calculation ApplyZoom sends to tmux
+func zoomLayout(node *LayoutNode, activePaneID int, opts zoomOptions) *LayoutNode {
+	result := copyLayoutNode(node)
+	path, ok := result.PanePath(activePaneID)
+	if !ok || len(path) == 0 {
+		return result
 	}
 
+	// Zoom at root level, then in any nested splits the active pane is in
+	zoomSplit(result, path[0], opts)
+	applyNestedZoom(result, path, opts)
 	return result
 }
 
-// containsPane checks if a node or its descendants contain the given pane ID
-func containsPane(node *LayoutNode, paneID int) bool {
-	if node.PaneID == paneID {
-		return true
-	}
-	for _, child := range node.Children {
-		if containsPane(child, paneID) {
-			return true
+// validZoomPercent reports whether percent is within the accepted range
+func validZoomPercent(percent int) bool {
+	return percent >= MinZoomPercent && percent <= MaxZoomPercent
+}
+
+// zoomSplit resizes the children of a split so the child at activeIdx gets the zoom percentage.
+// Children holding a pinned pane keep their size; if the active child holds one, nothing changes.
+// Splits along the other axis are left alone when opts.Axis limits zoom to one direction.
+func zoomSplit(node *LayoutNode, activeIdx int, opts zoomOptions) {
+	fixed := make([]bool, len(node.Children))
+	if len(opts.Pinned) > 0 {
+		for i, child := range node.Children {
+			for _, leaf := range child.Leaves() {
+				if opts.Pinned[leaf.PaneID] {
+					fixed[i] = true
+					break
+				}
+			}
 		}
 	}
-	return false
+	if fixed[activeIdx] {
+		return
+	}
+
+	if node.SplitType == SplitHorizontal && opts.Axis != axisVertical {
+		applyHorizontalZoom(node, activeIdx, opts.Percent, fixed)
+	} else if node.SplitType == SplitVertical && opts.Axis != axisHorizontal {
+		applyVerticalZoom(node, activeIdx, opts.Percent, fixed)
+	}
 }
 
-// countPanes returns the number of panes in a layout tree
-func countPanes(node *LayoutNode) int {
-	if node == nil {
-		return 0
+// zoomSizes calculates new sizes for a split's children along the split:
+// fixed children keep their size, the active child gets zoomPercent of the
+// rest and the others share what's left in proportion to their current size
+func zoomSizes(sizes []int, available int, activeIdx int, zoomPercent int, fixed []bool) []int {
+	// Space fixed children keep, and how many others have to shrink
+	fixedSize, others := 0, 0
+	for i, size := range sizes {
+		if i == activeIdx {
+			continue
+		}
+		if fixed[i] {
+			fixedSize += size
+		} else {
+			others++
+		}
 	}
-	if node.SplitType == SplitNone {
-		// Leaf node = 1 pane
-		return 1
+	flexible := available - fixedSize
+
+	// Target size for active child - a share of the space that isn't fixed
+	targetSize := (flexible * zoomPercent) / 100
+	if others == 0 {
+		targetSize = flexible
+	} else if targetSize > flexible-others {
+		// Leave every shrinking child at least one cell
+		targetSize = flexible - others
 	}
-	count := 0
-	for _, child := range node.Children {
-		count += countPanes(child)
+
+	// Total size of other children (for proportional distribution)
+	var otherSize int
+	for i, size := range sizes {
+		if i != activeIdx && !fixed[i] {
+			otherSize += size
+		}
+	}
+
+	// Remaining size for other children
+	remainingSize := flexible - targetSize
+
+	// Distribute sizes
+	newSizes := make([]int, len(sizes))
+	sizeUsed := 0
+	for i, size := range sizes {
+		if i == activeIdx {
+			newSizes[i] = targetSize
+		} else if fixed[i] {
+			newSizes[i] = size
+		} else if otherSize > 0 {
+			// Proportional share
+			newSizes[i] = (size * remainingSize) / otherSize
+		} else {
+			// Fallback: equal distribution
+			newSizes[i] = remainingSize / others
+		}
+		sizeUsed += newSizes[i]
 	}
-	return count
+
+	// Adjust for rounding errors - add remainder to active child
+	if sizeUsed != available {
+		newSizes[activeIdx] += available - sizeUsed
+	}
+	return newSizes
+}
+
+// countPanes returns the number of panes in a layout tree
+func countPanes(node *LayoutNode) int {
+	return len(node.Leaves())
 }
 
 // copyLayoutNode creates a deep copy of a layout node
@@ -305,7 +393,7 @@ func copyLayoutNode(node *LayoutNode) *LayoutNode {
 
 // applyHorizontalZoom resizes children of a horizontal split
 // Active child gets zoomPercent of width, others shrink proportionally
-func applyHorizontalZoom(node *LayoutNode, activeIdx int, zoomPercent int) {
+func applyHorizontalZoom(node *LayoutNode, activeIdx int, zoomPercent int, fixed []bool) {
 	if len(node.Children) <= 1 {
 		return
 	}
@@ -314,46 +402,17 @@ func applyHorizontalZoom(node *LayoutNode, activeIdx int, zoomPercent int) {
 	borders := len(node.Children) - 1
 	availableWidth := node.Width - borders
 
-	// Target width for active child
-	targetWidth := (availableWidth * zoomPercent) / 100
-
-	// Total width of other children (for proportional distribution)
-	var otherWidth int
-	for i, child := range node.Children {
-		if i != activeIdx {
-			otherWidth += child.Width
-		}
-	}
-
-	// Remaining width for other children
-	remainingWidth := availableWidth - targetWidth
-
-	// Distribute widths
-	newWidths := make([]int, len(node.Children))
-	widthUsed := 0
+	widths := make([]int, len(node.Children))
 	for i, child := range node.Children {
-		if i == activeIdx {
-			newWidths[i] = targetWidth
-		} else if otherWidth > 0 {
-			// Proportional share
-			newWidths[i] = (child.Width * remainingWidth) / otherWidth
-		} else {
-			// Fallback: equal distribution
-			newWidths[i] = remainingWidth / (len(node.Children) - 1)
-		}
-		widthUsed += newWidths[i]
-	}
-
-	// Adjust for rounding errors - add remainder to active child
-	if widthUsed != availableWidth {
-		newWidths[activeIdx] += availableWidth - widthUsed
+		widths[i] = child.Width
 	}
+	newWidths := zoomSizes(widths, availableWidth, activeIdx, zoomPercent, fixed)
 
 	// Apply new widths and update X positions
 	currentX := node.X
 	for i, child := range node.Children {
 		child.Width = newWidths[i]
-		child.X = currentX
+		moveNode(child, currentX-child.X, 0)
 		// Recursively update widths of all descendants
 		updateChildWidths(child, newWidths[i])
 		currentX += newWidths[i] + 1 // +1 for border
@@ -362,7 +421,7 @@ func applyHorizontalZoom(node *LayoutNode, activeIdx int, zoomPercent int) {
 
 // applyVerticalZoom resizes children of a vertical split
 // Active child gets zoomPercent of height, others shrink proportionally
-func applyVerticalZoom(node *LayoutNode, activeIdx int, zoomPercent int) {
+func applyVerticalZoom(node *LayoutNode, activeIdx int, zoomPercent int, fixed []bool) {
 	if len(node.Children) <= 1 {
 		return
 	}
@@ -371,52 +430,32 @@ func applyVerticalZoom(node *LayoutNode, activeIdx int, zoomPercent int) {
 	borders := len(node.Children) - 1
 	availableHeight := node.Height - borders
 
-	// Target height for active child
-	targetHeight := (availableHeight * zoomPercent) / 100
-
-	// Total height of other children (for proportional distribution)
-	var otherHeight int
+	heights := make([]int, len(node.Children))
 	for i, child := range node.Children {
-		if i != activeIdx {
-			otherHeight += child.Height
-		}
-	}
-
-	// Remaining height for other children
-	remainingHeight := availableHeight - targetHeight
-
-	// Distribute heights
-	newHeights := make([]int, len(node.Children))
-	heightUsed := 0
-	for i, child := range node.Children {
-		if i == activeIdx {
-			newHeights[i] = targetHeight
-		} else if otherHeight > 0 {
-			// Proportional share
-			newHeights[i] = (child.Height * remainingHeight) / otherHeight
-		} else {
-			// Fallback: equal distribution
-			newHeights[i] = remainingHeight / (len(node.Children) - 1)
-		}
-		heightUsed += newHeights[i]
-	}
-
-	// Adjust for rounding errors
-	if heightUsed != availableHeight {
-		newHeights[activeIdx] += availableHeight - heightUsed
+		heights[i] = child.Height
 	}
+	newHeights := zoomSizes(heights, availableHeight, activeIdx, zoomPercent, fixed)
 
 	// Apply new heights and update Y positions
 	currentY := node.Y
 	for i, child := range node.Children {
 		child.Height = newHeights[i]
-		child.Y = currentY
+		moveNode(child, 0, currentY-child.Y)
 		// Recursively update heights of all descendants
 		updateChildHeights(child, newHeights[i])
 		currentY += newHeights[i] + 1 // +1 for border
 	}
 }
 
+// moveNode shifts a node and all of its descendants by dx, dy
+func moveNode(node *LayoutNode, dx, dy int) {
+	node.Walk(func(n *LayoutNode) bool {
+		n.X += dx
+		n.Y += dy
+		return true
+	})
+}
+
 // updateChildWidths recursively updates width of all children to match parent
 func updateChildWidths(node *LayoutNode, width int) {
 	node.Width = width
@@ -468,40 +507,138 @@ func CaptureSnapshot() (*State, error) {
 
 // RestoreSnapshot restores the saved layout
 func RestoreSnapshot(state *State) error {
+	return RestoreSnapshotIn("", state)
+}
+
+// RestoreSnapshotIn restores the saved layout in a target window
+func RestoreSnapshotIn(target string, state *State) error {
 	if state.Snapshot == "" {
 		return nil
 	}
-	return SelectLayout(state.Snapshot)
+
+	snapshotTree, err := ParseLayout(state.Snapshot)
+	if err != nil {
+		return err
+	}
+	width, height, err := GetWindowSizeIn(target)
+	if err != nil {
+		return err
+	}
+	// select-layout unzooms a natively zoomed window, so zoom it again afterwards
+	zoomed, err := IsWindowZoomedIn(target)
+	if err != nil {
+		return err
+	}
+	layout := state.Snapshot
+	if snapshotTree.Width != width || snapshotTree.Height != height {
+		// The window was resized since the snapshot - scale it so tmux doesn't have to
+		debugf("RestoreSnapshot: scaling %dx%d snapshot to %dx%d",
+			snapshotTree.Width, snapshotTree.Height, width, height)
+		scaleLayout(snapshotTree, width, height)
+		layout = BuildLayout(snapshotTree)
+	}
+
+	if err := SelectLayoutIn(target, layout); err != nil {
+		return err
+	}
+
+	// tmux fills the layout with panes in window order, ignoring the IDs in the string
+	if err := arrangePanes(target, snapshotTree.paneIDs()); err != nil {
+		return err
+	}
+	if zoomed {
+		return ZoomActivePaneIn(target)
+	}
+	return nil
 }
 
-// ApplyZoom reads the CURRENT layout and enlarges the focused pane proportionally.
-// This is a stateless approach - we calculate zoom from the current layout each time,
-// not from a saved snapshot. This prevents stale state issues when panes change.
-func ApplyZoom(state *State) error {
-	// Check pane count - skip if only 1 pane
-	paneCount, err := GetPaneCount()
+// arrangePanes swaps panes until they sit in the layout in the given order
+func arrangePanes(target string, order []int) error {
+	panes, err := GetPanesIn(target)
 	if err != nil {
 		return err
 	}
-	if paneCount <= 1 {
+	if len(panes) != len(order) {
 		return nil
 	}
 
-	// Check if we're in the same session/window as when zoom was enabled
-	session, err := GetCurrentSession()
+	current := make([]string, len(panes))
+	active := ""
+	for i, p := range panes {
+		current[i] = p.ID
+		if p.Active {
+			active = p.ID
+		}
+	}
+
+	swapped := false
+	for i, id := range order {
+		want := fmt.Sprintf("%%%d", id)
+		if current[i] == want {
+			continue
+		}
+		j := -1
+		for k := i + 1; k < len(current); k++ {
+			if current[k] == want {
+				j = k
+				break
+			}
+		}
+		if j == -1 {
+			continue
+		}
+		if err := SwapPane(want, current[i]); err != nil {
+			return err
+		}
+		current[i], current[j] = current[j], current[i]
+		swapped = true
+	}
+
+	// swap-pane -d keeps the active position, so the active pane may have moved
+	if swapped && active != "" {
+		return SelectPane(active)
+	}
+	return nil
+}
+
+// scaleLayout resizes a whole layout tree to width x height, keeping each
+// split's proportions and one cell per border
+func scaleLayout(node *LayoutNode, width, height int) {
+	fitLayout(node, node.X, node.Y, width, height)
+}
+
+// ApplyZoom reads the CURRENT layout and enlarges the focused pane proportionally.
+// This is a stateless approach - we calculate zoom from the current layout each time,
+// not from a saved snapshot. This prevents stale state issues when panes change.
+func ApplyZoom(state *State) error {
+	// Runs on every focus change, so read the window in one tmux call
+	window, err := GetFormats("", "window_panes", "session_name", "window_index", "window_zoomed_flag", deferredOption)
 	if err != nil {
 		return err
 	}
-	window, err := GetCurrentWindow()
-	if err != nil {
+	paneCount, session, index, zoomed, deferred := window[0], window[1], window[2], window[3] == "1", window[4] != ""
+
+	// Skip if only 1 pane
+	if n, err := strconv.Atoi(paneCount); err != nil || n <= 1 {
 		return err
 	}
 
 	// Different window - don't apply zoom
-	if session != state.Session || window != state.Window {
+	if session != state.Session || index != state.Window {
 		return nil
 	}
 
+	// select-layout would undo tmux's own zoom; wait until the window is unzoomed
+	if zoomed {
+		debugf("Window is natively zoomed, deferring zoom")
+		return SetScopedOption("-w", deferredOption, "on")
+	}
+	if deferred {
+		if err := UnsetScopedOption("-w", deferredOption); err != nil {
+			return err
+		}
+	}
+
 	// Get active pane ID
 	activePaneID, err := GetActivePaneID()
 	if err != nil {
@@ -509,6 +646,16 @@ func ApplyZoom(state *State) error {
 	}
 	debugf("Active pane ID: %d", activePaneID)
 
+	// Pinned panes, ignore patterns and rules decide how (and whether) to zoom
+	opts, skip, err := GetZoomSettings(activePaneID)
+	if err != nil {
+		return err
+	}
+	if skip {
+		debugf("Active pane %d is pinned or skipped by a rule, not zooming", activePaneID)
+		return nil
+	}
+
 	// Read CURRENT layout (stateless approach - no snapshot dependency)
 	currentLayout, err := GetWindowLayout()
 	if err != nil {
@@ -524,14 +671,8 @@ func ApplyZoom(state *State) error {
 		return err
 	}
 
-	// Get configured zoom percentage
-	zoomPercent := GetZoomPercent()
-
-	// Apply zoom to the layout tree (horizontal zoom at root level)
-	zoomedTree := ApplyZoomToLayout(layoutTree, activePaneID, zoomPercent)
-
-	// Also apply vertical zoom if the active pane is in a column with multiple panes
-	applyNestedZoom(zoomedTree, activePaneID, zoomPercent)
+	// Apply zoom to the layout tree, including nested splits
+	zoomedTree := zoomLayout(layoutTree, activePaneID, opts)
 
 	// Build and apply the new layout
 	newLayout := BuildLayout(zoomedTree)
@@ -542,41 +683,21 @@ func ApplyZoom(state *State) error {
 		return err
 	}
 
-	return nil
+	// Keep #{@focus-zoom-effective-percent} in step for status formats
+	return publishPercent(opts.Percent)
 }
 
-// applyNestedZoom recursively applies zoom to nested splits containing the active pane
-func applyNestedZoom(node *LayoutNode, activePaneID int, zoomPercent int) {
-	// Find the child that contains the active pane
-	for i, child := range node.Children {
-		if containsPane(child, activePaneID) {
-			// If this child has children (is a split), apply zoom to it
-			if len(child.Children) > 1 {
-				// Find which grandchild contains the active pane
-				activeGrandchildIdx := -1
-				for j, grandchild := range child.Children {
-					if containsPane(grandchild, activePaneID) {
-						activeGrandchildIdx = j
-						break
-					}
-				}
-
-				if activeGrandchildIdx >= 0 {
-					// Apply zoom based on the child's split type
-					if child.SplitType == SplitHorizontal {
-						applyHorizontalZoom(child, activeGrandchildIdx, zoomPercent)
-					} else if child.SplitType == SplitVertical {
-						applyVerticalZoom(child, activeGrandchildIdx, zoomPercent)
-					}
-
-					// Recursively apply to deeper levels
-					applyNestedZoom(child, activePaneID, zoomPercent)
-				}
-			}
-			// Update this child's reference in parent
-			node.Children[i] = child
+// applyNestedZoom applies zoom to the nested splits below the root along path,
+// the child indices leading to the active pane
+func applyNestedZoom(node *LayoutNode, path []int, opts zoomOptions) {
+	// The root level is handled by zoomSplit on path[0]; walk down from its child
+	split := node
+	for depth := 1; depth < len(path); depth++ {
+		split = split.Children[path[depth-1]]
+		if len(split.Children) <= 1 {
 			return
 		}
+		zoomSplit(split, path[depth], opts)
 	}
 }
 
@@ -607,7 +728,7 @@ func applyZoomFallback(state *State, activePaneID int) error {
 	}
 
 	// Apply old proportional zoom logic
-	zoomPercent := GetZoomPercent()
+	zoomPercent := GetZoomPercent(activePane.ID)
 	applyProportionalZoom(panes, activePane, winWidth, winHeight, zoomPercent)
 	return nil
 }
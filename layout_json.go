@@ -0,0 +1,285 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// String returns the name used for the split type in JSON output
+func (t SplitType) String() string {
+	switch t {
+	case SplitNone:
+		return "pane"
+	case SplitHorizontal:
+		return "horizontal"
+	case SplitVertical:
+		return "vertical"
+	default:
+		return "unknown"
+	}
+}
+
+// layoutJSON is the JSON representation of a LayoutNode
+type layoutJSON struct {
+	Type     string        `json:"type"`
+	Width    int           `json:"width"`
+	Height   int           `json:"height"`
+	X        int           `json:"x"`
+	Y        int           `json:"y"`
+	Pane     *int          `json:"pane,omitempty"`
+	Children []*LayoutNode `json:"children,omitempty"`
+}
+
+// MarshalJSON encodes the node with named split types; pane is only set on leaves
+func (node *LayoutNode) MarshalJSON() ([]byte, error) {
+	out := layoutJSON{
+		Type:     node.SplitType.String(),
+		Width:    node.Width,
+		Height:   node.Height,
+		X:        node.X,
+		Y:        node.Y,
+		Children: node.Children,
+	}
+	if node.SplitType == SplitNone {
+		paneID := node.PaneID
+		out.Pane = &paneID
+	}
+	return json.Marshal(out)
+}
+
+// parseSplitType converts a JSON split type name back to a SplitType
+func parseSplitType(name string) (SplitType, error) {
+	switch name {
+	case "pane":
+		return SplitNone, nil
+	case "horizontal":
+		return SplitHorizontal, nil
+	case "vertical":
+		return SplitVertical, nil
+	default:
+		return SplitNone, fmt.Errorf("unknown node type %q", name)
+	}
+}
+
+// UnmarshalJSON decodes a node written by MarshalJSON
+func (node *LayoutNode) UnmarshalJSON(data []byte) error {
+	var in layoutJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	splitType, err := parseSplitType(in.Type)
+	if err != nil {
+		return err
+	}
+
+	*node = LayoutNode{
+		Width:     in.Width,
+		Height:    in.Height,
+		X:         in.X,
+		Y:         in.Y,
+		PaneID:    -1,
+		SplitType: splitType,
+		Children:  in.Children,
+	}
+	if in.Pane != nil {
+		node.PaneID = *in.Pane
+	}
+	return nil
+}
+
+// validateLayout checks a tree is something tmux will accept: leaves have pane IDs,
+// splits have children, and children fill their parent exactly (one cell per border)
+func validateLayout(node *LayoutNode) error {
+	if node.Width <= 0 || node.Height <= 0 {
+		return fmt.Errorf("node at %d,%d has invalid size %dx%d", node.X, node.Y, node.Width, node.Height)
+	}
+
+	if node.SplitType == SplitNone {
+		if node.PaneID < 0 {
+			return fmt.Errorf("pane at %d,%d has no pane ID", node.X, node.Y)
+		}
+		if len(node.Children) > 0 {
+			return fmt.Errorf("pane %d has children", node.PaneID)
+		}
+		return nil
+	}
+
+	if len(node.Children) == 0 {
+		return fmt.Errorf("%s split at %d,%d has no children", node.SplitType, node.X, node.Y)
+	}
+
+	total := len(node.Children) - 1 // borders
+	for _, child := range node.Children {
+		if child == nil {
+			return fmt.Errorf("%s split at %d,%d has a null child", node.SplitType, node.X, node.Y)
+		}
+		if err := validateLayout(child); err != nil {
+			return err
+		}
+		if node.SplitType == SplitHorizontal {
+			if child.Height != node.Height {
+				return fmt.Errorf("child at %d,%d has height %d, want %d", child.X, child.Y, child.Height, node.Height)
+			}
+			total += child.Width
+		} else {
+			if child.Width != node.Width {
+				return fmt.Errorf("child at %d,%d has width %d, want %d", child.X, child.Y, child.Width, node.Width)
+			}
+			total += child.Height
+		}
+	}
+
+	size := node.Height
+	if node.SplitType == SplitHorizontal {
+		size = node.Width
+	}
+	if total != size {
+		return fmt.Errorf("%s split at %d,%d: children and borders add up to %d, want %d",
+			node.SplitType, node.X, node.Y, total, size)
+	}
+	return nil
+}
+
+// cmdLayout handles "layout export" and "layout import"
+func cmdLayout(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("usage: tmux-focus-zoom layout <export|import>")
+	}
+
+	switch args[0] {
+	case "export":
+		return cmdLayoutExport(args[1:])
+	case "import":
+		return cmdLayoutImport(args[1:])
+	default:
+		return fmt.Errorf("unknown layout command: %s", args[0])
+	}
+}
+
+// cmdLayoutExport prints the current window's layout (or --layout) as JSON or a layout string
+func cmdLayoutExport(args []string) error {
+	fs := flag.NewFlagSet("layout export", flag.ContinueOnError)
+	layout := fs.String("layout", "", "layout string to export (default: current window)")
+	format := fs.String("format", "json", "output format: json or layout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *layout == "" {
+		current, err := GetWindowLayout()
+		if err != nil {
+			return fmt.Errorf("GetWindowLayout: %w", err)
+		}
+		*layout = current
+	}
+
+	tree, err := ParseLayout(*layout)
+	if err != nil {
+		return fmt.Errorf("ParseLayout: %w", err)
+	}
+
+	switch *format {
+	case "json":
+		data, err := json.MarshalIndent(tree, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+	case "layout":
+		fmt.Println(BuildLayout(tree))
+	default:
+		return fmt.Errorf("unknown format: %s", *format)
+	}
+	return nil
+}
+
+// cmdLayoutImport reads a JSON layout tree, builds the layout string with its
+// checksum and applies it to the current window (or prints it with --print)
+func cmdLayoutImport(args []string) error {
+	fs := flag.NewFlagSet("layout import", flag.ContinueOnError)
+	file := fs.String("file", "", "JSON file to import (default: read from stdin)")
+	printOnly := fs.Bool("print", false, "print the layout string instead of applying it")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var data []byte
+	var err error
+	if *file != "" {
+		data, err = os.ReadFile(*file)
+	} else {
+		data, err = io.ReadAll(os.Stdin)
+	}
+	if err != nil {
+		return fmt.Errorf("reading layout: %w", err)
+	}
+
+	layout, err := importLayout(data)
+	if err != nil {
+		return err
+	}
+
+	if *printOnly {
+		fmt.Println(layout)
+		return nil
+	}
+	panes, err := GetPanes()
+	if err != nil {
+		return fmt.Errorf("GetPanes: %w", err)
+	}
+	if err := matchLayoutPanes(layout, panes); err != nil {
+		return err
+	}
+	debugf("layout import: applying %s", layout)
+	// RestoreSnapshot puts each pane where the layout says, not in window order
+	if err := RestoreSnapshot(&State{Snapshot: layout}); err != nil {
+		return fmt.Errorf("RestoreSnapshot: %w", err)
+	}
+	return nil
+}
+
+// matchLayoutPanes checks that a layout has exactly the panes in the window
+func matchLayoutPanes(layout string, panes []PaneInfo) error {
+	tree, err := ParseLayout(layout)
+	if err != nil {
+		return err
+	}
+	inWindow := make(map[int]bool)
+	for _, pane := range panes {
+		if id, err := parsePaneID(pane.ID); err == nil {
+			inWindow[id] = true
+		}
+	}
+
+	seen := make(map[int]bool)
+	for _, id := range tree.paneIDs() {
+		if seen[id] {
+			return fmt.Errorf("layout has pane %%%d more than once", id)
+		}
+		if !inWindow[id] {
+			return fmt.Errorf("layout pane %%%d is not in the current window", id)
+		}
+		seen[id] = true
+	}
+	if len(seen) != len(inWindow) {
+		return fmt.Errorf("layout has %d panes, the current window has %d", len(seen), len(inWindow))
+	}
+	return nil
+}
+
+// importLayout decodes and validates a JSON layout tree and returns its layout string.
+// Offsets are recomputed from the sizes, so edits only need to keep sizes consistent.
+func importLayout(data []byte) (string, error) {
+	var tree LayoutNode
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return "", fmt.Errorf("invalid layout JSON: %w", err)
+	}
+	if err := validateLayout(&tree); err != nil {
+		return "", fmt.Errorf("invalid layout: %w", err)
+	}
+	fitLayout(&tree, 0, 0, tree.Width, tree.Height)
+	return BuildLayout(&tree), nil
+}
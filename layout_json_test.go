@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestLayoutJSONRoundTrip(t *testing.T) {
+	node, err := ParseLayout(cortexLayout)
+	if err != nil {
+		t.Fatalf("ParseLayout failed: %v", err)
+	}
+
+	data, err := json.Marshal(node)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	layout, err := importLayout(data)
+	if err != nil {
+		t.Fatalf("importLayout failed: %v", err)
+	}
+
+	// Same tree gives the same body, and the checksum is recomputed to match tmux's
+	if layout != cortexLayout {
+		t.Errorf("round trip: got %s, want %s", layout, cortexLayout)
+	}
+}
+
+func TestLayoutJSON_PaneZero(t *testing.T) {
+	node, err := ParseLayout("1234,199x53,0,0{99x53,0,0,0,99x53,100,0,1}")
+	if err != nil {
+		t.Fatalf("ParseLayout failed: %v", err)
+	}
+
+	data, err := json.Marshal(node)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if !strings.Contains(string(data), `"pane":0`) {
+		t.Errorf("expected pane 0 in JSON, got %s", data)
+	}
+	if strings.Count(string(data), `"pane":`) != 2 {
+		t.Errorf("expected pane only on the two leaves, got %s", data)
+	}
+}
+
+func TestImportLayout_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		json   string
+		errMsg string
+	}{
+		{
+			name:   "unknown type",
+			json:   `{"type":"diagonal","width":10,"height":10,"x":0,"y":0}`,
+			errMsg: "unknown node type",
+		},
+		{
+			name:   "leaf without pane",
+			json:   `{"type":"pane","width":10,"height":10,"x":0,"y":0}`,
+			errMsg: "no pane ID",
+		},
+		{
+			name:   "split without children",
+			json:   `{"type":"vertical","width":10,"height":10,"x":0,"y":0}`,
+			errMsg: "no children",
+		},
+		{
+			name: "children don't fill parent",
+			json: `{"type":"horizontal","width":100,"height":10,"x":0,"y":0,"children":[
+				{"type":"pane","width":40,"height":10,"x":0,"y":0,"pane":1},
+				{"type":"pane","width":40,"height":10,"x":41,"y":0,"pane":2}]}`,
+			errMsg: "add up to 81, want 100",
+		},
+		{
+			name:   "null child",
+			json:   `{"type":"horizontal","width":10,"height":5,"x":0,"y":0,"children":[null]}`,
+			errMsg: "null child",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := importLayout([]byte(tc.json))
+			if err == nil || !strings.Contains(err.Error(), tc.errMsg) {
+				t.Errorf("expected error containing %q, got %v", tc.errMsg, err)
+			}
+		})
+	}
+}
+
+func TestImportLayout_RecomputesOffsets(t *testing.T) {
+	// Both panes claim x=0; the second one starts after the first and its border
+	layout, err := importLayout([]byte(`{"type":"horizontal","width":21,"height":5,"x":0,"y":0,"children":[
+		{"type":"pane","width":10,"height":5,"x":0,"y":0,"pane":1},
+		{"type":"pane","width":10,"height":5,"x":0,"y":0,"pane":2}]}`))
+	if err != nil {
+		t.Fatalf("importLayout failed: %v", err)
+	}
+	if body := "21x5,0,0{10x5,0,0,1,10x5,11,0,2}"; !strings.HasSuffix(layout, body) {
+		t.Errorf("got %s, want body %s", layout, body)
+	}
+}
+
+func TestMatchLayoutPanes(t *testing.T) {
+	panes := []PaneInfo{{ID: "%0"}, {ID: "%1"}}
+
+	tests := []struct {
+		name   string
+		layout string
+		ok     bool
+	}{
+		{"same panes", "1234,21x5,0,0{10x5,0,0,1,10x5,11,0,0}", true},
+		{"pane not in window", "1234,21x5,0,0{10x5,0,0,1,10x5,11,0,5}", false},
+		{"pane twice", "1234,21x5,0,0{10x5,0,0,1,10x5,11,0,1}", false},
+		{"pane missing", "1234,21x5,0,0,1", false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := matchLayoutPanes(tc.layout, panes)
+			if tc.ok && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !tc.ok && err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
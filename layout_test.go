@@ -282,3 +282,27 @@ func TestTwoColumnLayout(t *testing.T) {
 		t.Errorf("Expected horizontal split {}, got vertical split []")
 	}
 }
+
+// TestApplyZoomToLayout_MovesNestedPanes checks panes inside a resized column
+// move with it instead of keeping their old offsets
+func TestApplyZoomToLayout_MovesNestedPanes(t *testing.T) {
+	layout := "95e4,120x40,0,0{60x40,0,0,0,59x40,61,0[59x20,61,0,1,59x19,61,21,2]}"
+
+	node, err := ParseLayout(layout)
+	if err != nil {
+		t.Fatalf("ParseLayout failed: %v", err)
+	}
+
+	zoomed := zoomLayout(node, 2, zoomOptions{Percent: DefaultZoomPercent})
+	col1 := zoomed.Children[1]
+	for _, pane := range col1.Children {
+		if pane.X != col1.X {
+			t.Errorf("Pane %d x: got %d, want %d (column x)", pane.PaneID, pane.X, col1.X)
+		}
+	}
+
+	expected := "5dbf,120x40,0,0{42x40,0,0,0,77x40,43,0[77x14,43,0,1,77x25,43,15,2]}"
+	if got := BuildLayout(zoomed); got != expected {
+		t.Errorf("BuildLayout: got %s, want %s", got, expected)
+	}
+}
@@ -1,18 +1,14 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
 	"time"
 )
 
-const (
-	// Status bar colors (Catppuccin Mocha yellow)
-	statusColor = "#f9e2af"
-	zoomIcon    = "󰍉"
-)
-
 var debugLog *os.File
 
 func initDebugLog() {
@@ -31,7 +27,7 @@ func debugf(format string, args ...interface{}) {
 
 func main() {
 	if len(os.Args) < 2 {
-		fmt.Fprintln(os.Stderr, "Usage: tmux-focus-zoom <toggle|apply|status>")
+		fmt.Fprintln(os.Stderr, "Usage: tmux-focus-zoom <toggle|enable|disable|set-percent|reset|grow|shrink|cycle|max|apply|refresh|status|render|preview|calc|layout|save|restore|list>")
 		os.Exit(1)
 	}
 
@@ -42,94 +38,321 @@ func main() {
 	var err error
 	switch cmd {
 	case "toggle":
-		err = cmdToggle()
+		err = cmdToggle(os.Args[2:])
+	case "enable":
+		err = cmdEnable(os.Args[2:])
+	case "disable":
+		err = cmdDisable(os.Args[2:])
+	case "set-percent":
+		err = cmdSetPercent(os.Args[2:])
+	case "reset":
+		err = cmdReset(os.Args[2:])
+	case "max":
+		err = cmdMax()
+	case "cycle":
+		err = cmdCycle()
+	case "grow":
+		err = cmdGrow()
+	case "shrink":
+		err = cmdShrink()
 	case "apply":
 		err = cmdApply()
+	case "refresh":
+		err = cmdRefresh(os.Args[2:])
 	case "status":
-		err = cmdStatus()
+		err = cmdStatus(os.Args[2:])
+	case "render":
+		err = cmdRender(os.Args[2:])
+	case "preview":
+		err = cmdPreview(os.Args[2:])
+	case "calc":
+		err = cmdCalc(os.Args[2:])
+	case "layout":
+		err = cmdLayout(os.Args[2:])
+	case "save":
+		err = cmdSave(os.Args[2:])
+	case "restore":
+		err = cmdRestore(os.Args[2:])
+	case "list":
+		err = cmdList()
 	default:
 		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", cmd)
 		os.Exit(1)
 	}
 
+	if errors.Is(err, errNoChange) {
+		os.Exit(2)
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 }
 
-// cmdToggle enables or disables focus-zoom
-func cmdToggle() error {
-	debugf("cmdToggle: loading state")
+// errNoChange is returned by commands that had nothing to do, so scripts can tell
+// a no-op (exit code 2) from a change (exit code 0)
+var errNoChange = errors.New("no change")
+
+// zoomAction is what toggle, enable and disable do to the zoom state
+type zoomAction int
+
+const (
+	actionToggle zoomAction = iota
+	actionEnable
+	actionDisable
+)
+
+// cmdToggle enables or disables focus-zoom in the current window, or with
+// --global in every window
+func cmdToggle(args []string) error {
+	return switchZoom("toggle", args, actionToggle)
+}
+
+// cmdEnable makes sure focus-zoom is on in the current window, or with --global in every window
+func cmdEnable(args []string) error {
+	return switchZoom("enable", args, actionEnable)
+}
+
+// cmdDisable makes sure focus-zoom is off in the current window, or with --global everywhere
+func cmdDisable(args []string) error {
+	return switchZoom("disable", args, actionDisable)
+}
+
+// switchZoom turns zoom on or off for the toggle, enable and disable commands.
+// Returns errNoChange if zoom is already in the requested state.
+func switchZoom(name string, args []string, action zoomAction) error {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	global := fs.Bool("global", false, "apply to every window on the server")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	debugf("%s: loading state", name)
 	state, err := LoadState()
 	if err != nil {
-		debugf("cmdToggle: LoadState error: %v", err)
+		debugf("%s: LoadState error: %v", name, err)
 		return fmt.Errorf("LoadState: %w", err)
 	}
-	debugf("cmdToggle: state.Enabled=%v", state.Enabled)
-
-	// Get current session/window to check if we're in the same window as state
-	currentSession, _ := GetCurrentSession()
-	currentWindow, _ := GetCurrentWindow()
-	sameWindow := state.Session == currentSession && state.Window == currentWindow
-	debugf("cmdToggle: state window=%s:%s, current=%s:%s, sameWindow=%v",
-		state.Session, state.Window, currentSession, currentWindow, sameWindow)
-
-	if state.Enabled && sameWindow {
-		// Disable: we're in the same window where zoom was enabled
-		debugf("cmdToggle: disabling (same window)")
-
-		// Check if snapshot is still valid (same pane count)
-		canRestore := false
-		if state.Snapshot != "" {
-			if snapshotTree, err := ParseLayout(state.Snapshot); err == nil {
-				snapshotPanes := countPanes(snapshotTree)
-				currentPanes, _ := GetPaneCount()
-				canRestore = snapshotPanes == currentPanes
-				debugf("cmdToggle: snapshot panes=%d, current panes=%d, canRestore=%v",
-					snapshotPanes, currentPanes, canRestore)
-			}
-		}
+	debugf("%s: state.Enabled=%v state.Global=%v", name, state.Enabled, state.Global)
 
-		if canRestore {
-			debugf("cmdToggle: restoring snapshot")
-			if err := RestoreSnapshot(state); err != nil {
-				debugf("cmdToggle: RestoreSnapshot error (ignored): %v", err)
-			}
-		} else {
-			debugf("cmdToggle: skipping restore (pane count changed)")
+	if *global {
+		on := state.Global
+		if action == actionDisable {
+			on = state.enabledAnywhere()
 		}
-
-		if err := ClearState(); err != nil {
-			debugf("cmdToggle: ClearState error: %v", err)
-			return fmt.Errorf("ClearState: %w", err)
+		if !wantZoom(action, on) {
+			return errNoChange
+		}
+		if on {
+			return disableGlobal(state)
 		}
-		return DisplayMessage("Focus zoom: OFF")
+		return enableGlobal(state)
+	}
+
+	// Get current session/window to check if zoom is on here
+	ref, err := GetCurrentWindowRef()
+	if err != nil {
+		debugf("%s: GetCurrentWindowRef error: %v", name, err)
+		return fmt.Errorf("GetCurrentWindowRef: %w", err)
 	}
+	windowState := state.ForWindow(ref)
+	debugf("%s: state window=%s:%s, current=%s:%s (%s), enabled here=%v",
+		name, state.Session, state.Window, ref.Session, ref.Window, ref.ID, windowState != nil)
 
-	// Enable: capture snapshot and apply zoom
-	debugf("cmdToggle: enabling, capturing snapshot")
+	if !wantZoom(action, windowState != nil) {
+		return errNoChange
+	}
+	var msg string
+	if windowState != nil {
+		msg, err = disableWindow(state, ref, windowState)
+	} else {
+		msg, err = enableWindow(state, ref)
+	}
+	if err != nil {
+		return err
+	}
+	return DisplayMessage(msg)
+}
+
+// wantZoom reports whether an action changes zoom that is currently on or off
+func wantZoom(action zoomAction, on bool) bool {
+	switch action {
+	case actionEnable:
+		return !on
+	case actionDisable:
+		return on
+	default:
+		return true
+	}
+}
+
+// enableWindow captures the current window's layout and zooms it.
+// Returns the message to show.
+func enableWindow(state *State, ref WindowRef) (string, error) {
+	debugf("enableWindow: capturing snapshot")
 	newState, err := CaptureSnapshot()
 	if err != nil {
-		debugf("cmdToggle: CaptureSnapshot error: %v", err)
-		return fmt.Errorf("CaptureSnapshot: %w", err)
+		debugf("enableWindow: CaptureSnapshot error: %v", err)
+		return "", fmt.Errorf("CaptureSnapshot: %w", err)
 	}
-	debugf("cmdToggle: snapshot=%s", newState.Snapshot)
+	debugf("enableWindow: snapshot=%s", newState.Snapshot)
+
+	// The window toggled by hand takes over from any auto-enabled entry
+	delete(state.Windows, ref.ID)
+	newState.Global = state.Global
+	newState.Windows = state.Windows
 
 	if err := SaveState(newState); err != nil {
-		debugf("cmdToggle: SaveState error: %v", err)
-		return fmt.Errorf("SaveState: %w", err)
+		debugf("enableWindow: SaveState error: %v", err)
+		return "", fmt.Errorf("SaveState: %w", err)
+	}
+	debugf("enableWindow: state saved")
+	if err := publishActive(ref.ID, true, state.Global); err != nil {
+		return "", fmt.Errorf("publishActive: %w", err)
+	}
+	// The window toggled on by hand before this one is no longer tracked, so
+	// it's off unless global mode still zooms it
+	if state.Enabled {
+		prev, err := GetWindowRefIn(state.Session + ":" + state.Window)
+		if err != nil {
+			debugf("enableWindow: previous window %s:%s gone (ignored): %v", state.Session, state.Window, err)
+		} else if newState.ForWindow(prev) == nil {
+			if err := publishActive(prev.ID, false, state.Global); err != nil {
+				debugf("enableWindow: publishActive error (ignored): %v", err)
+			}
+		}
 	}
-	debugf("cmdToggle: state saved")
 
 	// Apply zoom immediately
 	if err := ApplyZoom(newState); err != nil {
-		debugf("cmdToggle: ApplyZoom error: %v", err)
-		return fmt.Errorf("ApplyZoom: %w", err)
+		debugf("enableWindow: ApplyZoom error: %v", err)
+		return "", fmt.Errorf("ApplyZoom: %w", err)
+	}
+	debugf("enableWindow: zoom applied")
+
+	return "Focus zoom: ON", nil
+}
+
+// disableWindow restores the current window's snapshot, whether zoom was
+// turned on by hand, automatically or by global mode. Returns the message to show.
+func disableWindow(state *State, ref WindowRef, windowState *State) (string, error) {
+	debugf("disableWindow: disabling %s", ref.ID)
+
+	// Restore from a copy, since the manual window's state is cleared below
+	restoreState := *windowState
+	if windowState == state {
+		state.Enabled = false
+		state.Session, state.Window, state.Snapshot = "", "", ""
+	}
+	// Keep auto-enabled and global windows off until toggled on again
+	if _, ok := state.Windows[ref.ID]; ok || state.Global || autoEnabled() {
+		if state.Windows == nil {
+			state.Windows = make(map[string]*WindowState)
+		}
+		state.Windows[ref.ID] = &WindowState{Session: ref.Session, Window: ref.Window, Disabled: true}
+	}
+
+	// Save first so hooks fired while restoring don't re-apply zoom
+	if err := SaveOrClearState(state); err != nil {
+		debugf("disableWindow: SaveOrClearState error: %v", err)
+		return "", fmt.Errorf("SaveOrClearState: %w", err)
+	}
+	if err := publishActive(ref.ID, false, state.Global); err != nil {
+		return "", fmt.Errorf("publishActive: %w", err)
+	}
+	clearMax(ref.ID)
+
+	// Compare the snapshot's panes with the window's to decide how to restore
+	mode, err := RestoreSnapshotForPanes(&restoreState)
+	if err != nil {
+		debugf("disableWindow: restore error (ignored): %v", err)
+	}
+	debugf("disableWindow: restore mode=%s", mode)
+	return restoreMessage(mode), nil
+}
+
+// enableGlobal turns zoom on in every window. It only marks the state global:
+// each window's snapshot is captured the first time it's zoomed.
+func enableGlobal(state *State) error {
+	debugf("enableGlobal: enabling")
+	state.Global = true
+	// Windows toggled off by hand are on again
+	var reenabled []string
+	for id, ws := range state.Windows {
+		if ws.Disabled {
+			delete(state.Windows, id)
+			reenabled = append(reenabled, id)
+		}
+	}
+	if err := SaveState(state); err != nil {
+		return fmt.Errorf("SaveState: %w", err)
+	}
+
+	if err := publishGlobal(true); err != nil {
+		return fmt.Errorf("publishGlobal: %w", err)
+	}
+	for _, id := range reenabled {
+		// Drop the window's 0 so the global 1 applies
+		if err := publishActive(id, false, false); err != nil {
+			debugf("enableGlobal: publishActive %s error (ignored): %v", id, err)
+		}
+	}
+
+	windowState, err := CurrentWindowState(state)
+	if err != nil {
+		return err
+	}
+	if windowState != nil {
+		if err := ApplyZoom(windowState); err != nil {
+			return fmt.Errorf("ApplyZoom: %w", err)
+		}
+	}
+	return DisplayMessage("Focus zoom: ON (all windows)")
+}
+
+// disableGlobal turns zoom off everywhere it's on, restoring each window's own snapshot
+func disableGlobal(state *State) error {
+	debugf("disableGlobal: disabling")
+	pruneWindows(state)
+
+	// Windows to restore, by tmux target, and every window with published options
+	restore := make(map[string]*State)
+	published := []string{}
+	for id, ws := range state.Windows {
+		published = append(published, id)
+		if !ws.Disabled {
+			restore[id] = &State{Snapshot: ws.Snapshot}
+		}
+	}
+	if state.Enabled {
+		target := state.Session + ":" + state.Window
+		published = append(published, target)
+		restore[target] = &State{Snapshot: state.Snapshot}
 	}
-	debugf("cmdToggle: zoom applied")
 
-	return DisplayMessage("Focus zoom: ON")
+	// Clear first so hooks fired while restoring don't re-apply zoom
+	if err := ClearState(); err != nil {
+		return fmt.Errorf("ClearState: %w", err)
+	}
+
+	if err := publishGlobal(false); err != nil {
+		return fmt.Errorf("publishGlobal: %w", err)
+	}
+	for _, target := range published {
+		if err := publishActive(target, false, false); err != nil {
+			debugf("disableGlobal: publishActive %s error (ignored): %v", target, err)
+		}
+	}
+
+	for target, windowState := range restore {
+		clearMax(target)
+		mode, err := RestoreSnapshotForPanesIn(target, windowState)
+		if err != nil {
+			debugf("disableGlobal: restore %s error (ignored): %v", target, err)
+		}
+		debugf("disableGlobal: restored %s, mode=%s", target, mode)
+	}
+	return DisplayMessage("Focus zoom: OFF (all windows)")
 }
 
 // cmdApply is called on pane-focus-in to apply zoom effect
@@ -138,29 +361,52 @@ func cmdApply() error {
 	if err != nil {
 		return err
 	}
-
-	if !state.Enabled {
+	// Runs on every focus change, so do nothing more while zoom is off everywhere
+	if !state.enabledAnywhere() && !autoEnabled() {
 		return nil
 	}
 
-	return ApplyZoom(state)
+	windowState, err := CurrentWindowState(state)
+	if err != nil || windowState == nil {
+		return err
+	}
+
+	// A focus change ends max mode
+	if err := clearMaxOnFocus(); err != nil {
+		return err
+	}
+
+	return ApplyZoom(windowState)
 }
 
-// cmdStatus outputs the status for the tmux status bar
-func cmdStatus() error {
+// cmdRefresh is called on layout changes (split, pane exit, resize) to re-zoom
+// the active pane. Unlike apply it isn't tied to a focus change.
+// With --deferred it only re-zooms if zoom was put off while the window was
+// natively zoomed and the window is now unzoomed, so manual resizes are kept.
+func cmdRefresh(args []string) error {
+	fs := flag.NewFlagSet("refresh", flag.ContinueOnError)
+	deferred := fs.Bool("deferred", false, "only re-apply zoom deferred by tmux's native zoom")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *deferred {
+		ready, err := takeDeferredZoom()
+		if err != nil || !ready {
+			return err
+		}
+	}
+
 	state, err := LoadState()
-	if err != nil || !state.Enabled {
-		fmt.Printf("#[fg=%s]%s OFF#[default] ", statusColor, zoomIcon)
-		return nil
+	if err != nil {
+		return err
 	}
 
-	// Check if current window matches the state
-	match, err := IsMatchingWindow(state)
-	if err != nil || !match {
-		fmt.Printf("#[fg=%s]%s OFF#[default] ", statusColor, zoomIcon)
-		return nil
+	windowState, err := CurrentWindowState(state)
+	if err != nil || windowState == nil {
+		return err
 	}
 
-	fmt.Printf("#[fg=%s]%s ON#[default] ", statusColor, zoomIcon)
-	return nil
+	debugf("cmdRefresh: re-applying zoom")
+	return ApplyZoom(windowState)
 }
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+)
+
+const (
+	// maxPaneOption is the window option holding the pane maximised by the max
+	// command, e.g. "%3"
+	maxPaneOption = "@focus-zoom-max"
+)
+
+// GetMaxPane returns the maximised pane of the window holding target, or "" if none.
+// An empty target means the current window.
+func GetMaxPane(target string) string {
+	pane, _ := GetScopedOptionIn("-w", target, maxPaneOption)
+	return pane
+}
+
+// cmdMax zooms the active pane to MaxZoomPercent, leaving a sliver of the other
+// panes, until focus moves to another pane. Pressing it again returns to the
+// regular zoom percentage.
+func cmdMax() error {
+	state, err := LoadState()
+	if err != nil {
+		return fmt.Errorf("LoadState: %w", err)
+	}
+	windowState, err := CurrentWindowState(state)
+	if err != nil {
+		return err
+	}
+	if windowState == nil {
+		return DisplayMessage("Focus zoom: OFF in this window, toggle it on to maximise")
+	}
+
+	pane, err := GetCurrentPane()
+	if err != nil {
+		return fmt.Errorf("GetCurrentPane: %w", err)
+	}
+
+	msg := fmt.Sprintf("Focus zoom: %d%% (max)", MaxZoomPercent)
+	if GetMaxPane("") == pane {
+		debugf("cmdMax: un-maximising %s", pane)
+		if err := UnsetScopedOption("-w", maxPaneOption); err != nil {
+			return fmt.Errorf("UnsetScopedOption: %w", err)
+		}
+		msg = fmt.Sprintf("Focus zoom: %d%%", GetZoomPercent(pane))
+	} else {
+		debugf("cmdMax: maximising %s", pane)
+		if err := SetScopedOption("-w", maxPaneOption, pane); err != nil {
+			return fmt.Errorf("SetScopedOption: %w", err)
+		}
+	}
+
+	if err := ApplyZoom(windowState); err != nil {
+		return fmt.Errorf("ApplyZoom: %w", err)
+	}
+	return DisplayMessage(msg)
+}
+
+// clearMaxOnFocus drops the maximised pane once focus has moved away from it
+func clearMaxOnFocus() error {
+	maxPane := GetMaxPane("")
+	if maxPane == "" {
+		return nil
+	}
+	pane, err := GetCurrentPane()
+	if err != nil {
+		return fmt.Errorf("GetCurrentPane: %w", err)
+	}
+	if pane == maxPane {
+		return nil
+	}
+	debugf("clearMaxOnFocus: focus moved from %s to %s", maxPane, pane)
+	return UnsetScopedOption("-w", maxPaneOption)
+}
+
+// clearMax drops a window's maximised pane when zoom is turned off there, since
+// focus changes aren't tracked while it's off
+func clearMax(target string) {
+	if err := UnsetScopedOptionIn("-w", target, maxPaneOption); err != nil {
+		debugf("clearMax: %s error (ignored): %v", target, err)
+	}
+}
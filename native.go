@@ -0,0 +1,22 @@
+package main
+
+const (
+	// deferredOption is set on a window when zoom was skipped because a pane
+	// was natively zoomed with resize-pane -Z (prefix + z)
+	deferredOption = "@focus-zoom-deferred"
+)
+
+// takeDeferredZoom reports whether the current window has zoom deferred by
+// native zoom and is now unzoomed, clearing the flag if so
+func takeDeferredZoom() (bool, error) {
+	deferred, err := GetScopedOption("-w", deferredOption)
+	if err != nil || deferred == "" {
+		return false, err
+	}
+	zoomed, err := IsWindowZoomed()
+	if err != nil || zoomed {
+		return false, err
+	}
+	debugf("takeDeferredZoom: window unzoomed, applying deferred zoom")
+	return true, UnsetScopedOption("-w", deferredOption)
+}
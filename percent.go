@@ -0,0 +1,162 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+)
+
+const (
+	DefaultZoomStep = 5
+)
+
+// GetZoomStep returns @focus-zoom-step, how much grow and shrink change the
+// zoom percentage. Falls back to DefaultZoomStep.
+func GetZoomStep() int {
+	value, ok := lookupOption("@focus-zoom-step", "", func(v string) bool {
+		n, err := strconv.Atoi(v)
+		return err == nil && n >= 1
+	})
+	if !ok {
+		return DefaultZoomStep
+	}
+	n, _ := strconv.Atoi(value)
+	return n
+}
+
+// stepPercent moves a zoom percentage by delta, clamped to the valid range
+func stepPercent(percent, delta int) int {
+	return clampInt(percent+delta, MinZoomPercent, MaxZoomPercent)
+}
+
+// percentScope returns the option scope set-percent and reset change:
+// the current window, or global with --global
+func percentScope(global bool) string {
+	if global {
+		return "-g"
+	}
+	return "-w"
+}
+
+// cmdSetPercent sets @focus-zoom-percent for the current window (or globally)
+// and re-zooms. Returns errNoChange if it's already set to that value.
+func cmdSetPercent(args []string) error {
+	fs := flag.NewFlagSet("set-percent", flag.ContinueOnError)
+	global := fs.Bool("global", false, "set the global option instead of the window's")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: tmux-focus-zoom set-percent [--global] <percent>")
+	}
+	percent, err := strconv.Atoi(fs.Arg(0))
+	if err != nil || !validZoomPercent(percent) {
+		return fmt.Errorf("percent must be between %d and %d, got %q", MinZoomPercent, MaxZoomPercent, fs.Arg(0))
+	}
+
+	scope := percentScope(*global)
+	current, err := GetScopedOption(scope, "@focus-zoom-percent")
+	if err != nil {
+		return fmt.Errorf("GetScopedOption: %w", err)
+	}
+	if current == strconv.Itoa(percent) {
+		return errNoChange
+	}
+	if err := SetScopedOption(scope, "@focus-zoom-percent", strconv.Itoa(percent)); err != nil {
+		return fmt.Errorf("SetScopedOption: %w", err)
+	}
+
+	if err := reapplyZoom(); err != nil {
+		return err
+	}
+	return DisplayMessage(percentMessage(percent))
+}
+
+// cmdReset removes the current window's (or the global) @focus-zoom-percent so the
+// next scope's value or the default applies, and re-zooms.
+// Returns errNoChange if it wasn't set.
+func cmdReset(args []string) error {
+	fs := flag.NewFlagSet("reset", flag.ContinueOnError)
+	global := fs.Bool("global", false, "reset the global option instead of the window's")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	scope := percentScope(*global)
+	current, err := GetScopedOption(scope, "@focus-zoom-percent")
+	if err != nil {
+		return fmt.Errorf("GetScopedOption: %w", err)
+	}
+	if current == "" {
+		return errNoChange
+	}
+	if err := UnsetScopedOption(scope, "@focus-zoom-percent"); err != nil {
+		return fmt.Errorf("UnsetScopedOption: %w", err)
+	}
+
+	if err := reapplyZoom(); err != nil {
+		return err
+	}
+	return DisplayMessage(fmt.Sprintf("Focus zoom: %d%%", GetZoomPercent("")))
+}
+
+// reapplyZoom re-zooms the current window if zoom is on there, e.g. after a
+// setting changed
+func reapplyZoom() error {
+	state, err := LoadState()
+	if err != nil {
+		return fmt.Errorf("LoadState: %w", err)
+	}
+	windowState, err := CurrentWindowState(state)
+	if err != nil || windowState == nil {
+		return err
+	}
+	if err := ApplyZoom(windowState); err != nil {
+		return fmt.Errorf("ApplyZoom: %w", err)
+	}
+	return nil
+}
+
+// cmdGrow makes the focused pane bigger by @focus-zoom-step
+func cmdGrow() error {
+	return stepZoom(GetZoomStep())
+}
+
+// cmdShrink makes the focused pane smaller by @focus-zoom-step
+func cmdShrink() error {
+	return stepZoom(-GetZoomStep())
+}
+
+// stepZoom changes the current window's @focus-zoom-percent by delta and re-zooms.
+// At the 10-95% limits it only shows a message: grow and shrink are bound to
+// keys, and a non-zero exit from run-shell puts the pane into view mode.
+func stepZoom(delta int) error {
+	current := GetWindowZoomPercent()
+	percent := stepPercent(current, delta)
+	debugf("stepZoom: %d%% -> %d%%", current, percent)
+	if percent == current {
+		return DisplayMessage(fmt.Sprintf("Focus zoom: %d%% (limit)", percent))
+	}
+	if err := SetScopedOption("-w", "@focus-zoom-percent", strconv.Itoa(percent)); err != nil {
+		return fmt.Errorf("SetScopedOption: %w", err)
+	}
+
+	if err := reapplyZoom(); err != nil {
+		return err
+	}
+	return DisplayMessage(percentMessage(percent))
+}
+
+// percentMessage reports a new zoom percentage for the window, noting when the
+// focused pane's own @focus-zoom-percent, a rule or max mode overrides it
+func percentMessage(percent int) string {
+	msg := fmt.Sprintf("Focus zoom: %d%%", percent)
+	pane, err := GetCurrentPane()
+	if err != nil {
+		return msg
+	}
+	if effective := effectivePercent(pane); effective != percent {
+		msg += fmt.Sprintf(" (this pane: %d%%)", effective)
+	}
+	return msg
+}
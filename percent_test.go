@@ -0,0 +1,51 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestStepPercent(t *testing.T) {
+	tests := []struct {
+		percent, delta, expected int
+	}{
+		{65, 5, 70},
+		{65, -5, 60},
+		{93, 5, MaxZoomPercent},
+		{12, -5, MinZoomPercent},
+		{MaxZoomPercent, 5, MaxZoomPercent},
+	}
+
+	for _, tc := range tests {
+		if got := stepPercent(tc.percent, tc.delta); got != tc.expected {
+			t.Errorf("stepPercent(%d, %d) = %d, want %d", tc.percent, tc.delta, got, tc.expected)
+		}
+	}
+}
+
+func TestGetZoomStep(t *testing.T) {
+	fake := newFakeTmux(t)
+
+	if got := GetZoomStep(); got != DefaultZoomStep {
+		t.Errorf("unset: got %d, want %d", got, DefaultZoomStep)
+	}
+
+	fake.set("global", "@focus-zoom-step", "10")
+	fake.set("window", "@focus-zoom-step", "-3")
+	if got := GetZoomStep(); got != 10 {
+		t.Errorf("invalid window value: got %d, want 10", got)
+	}
+}
+
+func TestGetWindowZoomPercent(t *testing.T) {
+	fake := newFakeTmux(t)
+	fake.set("global", "@focus-zoom-percent", "60")
+	fake.set("pane", "@focus-zoom-percent", "50")
+
+	// The pane's value is what it zooms to, but grow and shrink step the window's
+	if got := GetZoomPercent(""); got != 50 {
+		t.Errorf("GetZoomPercent = %d, want 50", got)
+	}
+	if got := GetWindowZoomPercent(); got != 60 {
+		t.Errorf("GetWindowZoomPercent = %d, want 60", got)
+	}
+}
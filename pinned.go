@@ -0,0 +1,33 @@
+package main
+
+import (
+	"path"
+)
+
+// isIgnoredPane reports whether a pane keeps its size: it is pinned with
+// @focus-zoom-pinned or its command matches one of the ignore patterns
+func isIgnoredPane(pane PaneInfo, patterns []string) bool {
+	if pane.Pinned {
+		return true
+	}
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, pane.CurrentCommand); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// pinnedPanes returns the numeric IDs of the panes that keep their size
+func pinnedPanes(panes []PaneInfo, patterns []string) map[int]bool {
+	pinned := make(map[int]bool)
+	for _, pane := range panes {
+		if !isIgnoredPane(pane, patterns) {
+			continue
+		}
+		if id, err := parsePaneID(pane.ID); err == nil {
+			pinned[id] = true
+		}
+	}
+	return pinned
+}
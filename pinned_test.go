@@ -0,0 +1,78 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestIsIgnoredPane(t *testing.T) {
+	patterns := []string{"tail", "htop*"}
+
+	tests := []struct {
+		name     string
+		pane     PaneInfo
+		expected bool
+	}{
+		{"pinned option", PaneInfo{ID: "%1", Pinned: true, CurrentCommand: "zsh"}, true},
+		{"exact command", PaneInfo{ID: "%2", CurrentCommand: "tail"}, true},
+		{"glob command", PaneInfo{ID: "%3", CurrentCommand: "htop-dev"}, true},
+		{"other command", PaneInfo{ID: "%4", CurrentCommand: "nvim"}, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := isIgnoredPane(tc.pane, patterns); got != tc.expected {
+				t.Errorf("isIgnoredPane(%+v) = %v, want %v", tc.pane, got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestPinnedPanes(t *testing.T) {
+	panes := []PaneInfo{
+		{ID: "%26", CurrentCommand: "nvim"},
+		{ID: "%41", CurrentCommand: "tail"},
+		{ID: "%42", Pinned: true},
+	}
+
+	pinned := pinnedPanes(panes, []string{"tail"})
+	if len(pinned) != 2 || !pinned[41] || !pinned[42] {
+		t.Errorf("pinnedPanes: got %v, want 41 and 42", pinned)
+	}
+}
+
+// TestZoomLayout_PinnedColumnKeepsWidth checks a pinned pane's column keeps its width
+// while the active column and the others share the rest
+func TestZoomLayout_PinnedColumnKeepsWidth(t *testing.T) {
+	node, err := ParseLayout(cortexLayout)
+	if err != nil {
+		t.Fatalf("ParseLayout failed: %v", err)
+	}
+
+	zoomed := zoomLayout(node, 36, zoomOptions{Percent: DefaultZoomPercent, Pinned: map[int]bool{42: true}})
+
+	col0, col1, col2 := zoomed.Children[0], zoomed.Children[1], zoomed.Children[2]
+	if col2.Width != 84 {
+		t.Errorf("pinned col2 width: got %d, want 84", col2.Width)
+	}
+	// 253 - 84 fixed = 169 flexible; 65% of that is 109
+	if col1.Width != 109 || col0.Width != 60 {
+		t.Errorf("widths: got col0=%d col1=%d, want col0=60 col1=109", col0.Width, col1.Width)
+	}
+	if err := validateLayout(zoomed); err != nil {
+		t.Errorf("zoomed layout is invalid: %v", err)
+	}
+}
+
+// TestZoomLayout_PinnedActiveColumn checks nothing moves when the active pane
+// shares its column with a pinned pane
+func TestZoomLayout_PinnedActiveColumn(t *testing.T) {
+	node, err := ParseLayout(cortexLayout)
+	if err != nil {
+		t.Fatalf("ParseLayout failed: %v", err)
+	}
+
+	zoomed := zoomLayout(node, 26, zoomOptions{Percent: DefaultZoomPercent, Pinned: map[int]bool{41: true}})
+	if got := BuildLayout(zoomed); got != cortexLayout {
+		t.Errorf("expected unchanged layout, got %s", got)
+	}
+}
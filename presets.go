@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	DefaultZoomPresets = "50,65,80,off"
+
+	// presetOff is the preset that turns zoom off
+	presetOff = "off"
+)
+
+// parsePresets splits a comma or space separated preset list, keeping
+// valid percentages and "off"
+func parsePresets(value string) []string {
+	var presets []string
+	for _, field := range strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' '
+	}) {
+		field = strings.ToLower(field)
+		if field == presetOff {
+			presets = append(presets, field)
+			continue
+		}
+		percent, err := strconv.Atoi(strings.TrimSuffix(field, "%"))
+		if err != nil || !validZoomPercent(percent) {
+			debugf("Ignoring invalid preset %q", field)
+			continue
+		}
+		presets = append(presets, strconv.Itoa(percent))
+	}
+	return presets
+}
+
+// GetZoomPresets returns the presets from @focus-zoom-presets for a pane, or
+// DefaultZoomPresets if none are set or valid. An empty paneID means the current pane.
+func GetZoomPresets(paneID string) []string {
+	return presetsOrDefault(GetOption("@focus-zoom-presets", paneID))
+}
+
+// presetsOrDefault parses a @focus-zoom-presets value, falling back to DefaultZoomPresets
+func presetsOrDefault(value string) []string {
+	if presets := parsePresets(value); len(presets) > 0 {
+		return presets
+	}
+	return parsePresets(DefaultZoomPresets)
+}
+
+// presetIndex returns the index of the preset matching the window's zoom, or -1
+func presetIndex(presets []string, on bool, percent int) int {
+	current := presetOff
+	if on {
+		current = strconv.Itoa(percent)
+	}
+	for i, preset := range presets {
+		if preset == current {
+			return i
+		}
+	}
+	return -1
+}
+
+// windowPreset returns the preset a window was cycled to: its window-scope
+// @focus-zoom-percent value if that's one of the presets, or "" otherwise
+func windowPreset(value string, presets []string) string {
+	percent, err := strconv.Atoi(value)
+	if err != nil || presetIndex(presets, true, percent) < 0 {
+		return ""
+	}
+	return value
+}
+
+// nextPreset returns the index of the preset after the window's current one.
+// A window not on any preset starts from the first.
+func nextPreset(presets []string, on bool, percent int) int {
+	return (presetIndex(presets, on, percent) + 1) % len(presets)
+}
+
+// cmdCycle moves the current window to the next zoom preset
+func cmdCycle() error {
+	state, err := LoadState()
+	if err != nil {
+		return fmt.Errorf("LoadState: %w", err)
+	}
+	ref, err := GetCurrentWindowRef()
+	if err != nil {
+		return fmt.Errorf("GetCurrentWindowRef: %w", err)
+	}
+	windowState, err := CurrentWindowState(state)
+	if err != nil {
+		return err
+	}
+	on := windowState != nil
+
+	presets := GetZoomPresets("")
+	next := nextPreset(presets, on, GetWindowZoomPercent())
+	preset := presets[next]
+	debugf("cmdCycle: presets=%v on=%v next=%s", presets, on, preset)
+
+	var msg string
+	if preset == presetOff {
+		if !on {
+			return DisplayMessage("Focus zoom: OFF")
+		}
+		msg, err = disableWindow(state, ref, windowState)
+		if err != nil {
+			return err
+		}
+	} else {
+		if err := SetScopedOption("-w", "@focus-zoom-percent", preset); err != nil {
+			return fmt.Errorf("SetScopedOption: %w", err)
+		}
+		if on {
+			err = ApplyZoom(windowState)
+		} else {
+			_, err = enableWindow(state, ref)
+		}
+		if err != nil {
+			return err
+		}
+		percent, _ := strconv.Atoi(preset)
+		msg = percentMessage(percent)
+	}
+	return DisplayMessage(fmt.Sprintf("%s (preset %d/%d)", msg, next+1, len(presets)))
+}
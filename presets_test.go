@@ -0,0 +1,60 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePresets(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected []string
+	}{
+		{"50,65,80,off", []string{"50", "65", "80", "off"}},
+		{"40% 90%, OFF", []string{"40", "90", "off"}},
+		{"5,abc,70", []string{"70"}},
+		{"", nil},
+	}
+
+	for _, tc := range tests {
+		if got := parsePresets(tc.value); !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("parsePresets(%q) = %v, want %v", tc.value, got, tc.expected)
+		}
+	}
+}
+
+func TestNextPreset(t *testing.T) {
+	presets := []string{"50", "65", "80", "off"}
+
+	tests := []struct {
+		name     string
+		on       bool
+		percent  int
+		expected int
+	}{
+		{"off to first", false, 65, 0},
+		{"middle", true, 65, 2},
+		{"last to off", true, 80, 3},
+		{"not a preset", true, 72, 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := nextPreset(presets, tc.on, tc.percent); got != tc.expected {
+				t.Errorf("nextPreset(on=%v, %d) = %d, want %d", tc.on, tc.percent, got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestGetZoomPresetsDefault(t *testing.T) {
+	fake := newFakeTmux(t)
+
+	if got := GetZoomPresets(""); !reflect.DeepEqual(got, []string{"50", "65", "80", "off"}) {
+		t.Errorf("unset: got %v", got)
+	}
+	fake.set("window", "@focus-zoom-presets", "60,off")
+	if got := GetZoomPresets(""); !reflect.DeepEqual(got, []string{"60", "off"}) {
+		t.Errorf("window presets: got %v", got)
+	}
+}
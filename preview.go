@@ -0,0 +1,82 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+)
+
+const (
+	// previewWidth is the width of each diagram in the preview
+	previewWidth = 50
+)
+
+// cmdPreview shows what zooming would do to the current window without applying it
+func cmdPreview(args []string) error {
+	fs := flag.NewFlagSet("preview", flag.ContinueOnError)
+	pane := fs.String("pane", "", "pane to zoom, e.g. %3 (default: active pane)")
+	percent := fs.Int("percent", 0, "zoom percentage (default: from rules or @focus-zoom-percent)")
+	width := fs.Int("width", previewWidth, "width of each diagram in columns")
+	ascii := fs.Bool("ascii", false, "draw with ASCII characters only")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var paneID int
+	var err error
+	if *pane != "" {
+		paneID, err = parsePaneID(*pane)
+		if err != nil {
+			return fmt.Errorf("invalid pane %q: %w", *pane, err)
+		}
+	} else {
+		paneID, err = GetActivePaneID()
+		if err != nil {
+			return fmt.Errorf("GetActivePaneID: %w", err)
+		}
+	}
+
+	if *percent != 0 && !validZoomPercent(*percent) {
+		return fmt.Errorf("percent must be between %d and %d, got %d", MinZoomPercent, MaxZoomPercent, *percent)
+	}
+	if *width < 2 {
+		return fmt.Errorf("invalid width: %d", *width)
+	}
+
+	currentLayout, err := GetWindowLayout()
+	if err != nil {
+		return fmt.Errorf("GetWindowLayout: %w", err)
+	}
+	before, err := ParseLayout(currentLayout)
+	if err != nil {
+		return fmt.Errorf("ParseLayout: %w", err)
+	}
+	if _, ok := before.PanePath(paneID); !ok {
+		return fmt.Errorf("pane %%%d is not in the current window", paneID)
+	}
+
+	opts, skip, err := GetZoomSettings(paneID)
+	if err != nil {
+		return fmt.Errorf("GetZoomSettings: %w", err)
+	}
+	if skip {
+		fmt.Printf("Pane %%%d is pinned or skipped by a rule, focusing it doesn't zoom\n", paneID)
+		return nil
+	}
+	if *percent != 0 {
+		opts.Percent = *percent
+	}
+
+	after := zoomLayout(before, paneID, opts)
+
+	fmt.Printf("Pane:   %%%d at %d%%\n", paneID, opts.Percent)
+	fmt.Printf("Before: %s\n", currentLayout)
+	fmt.Printf("After:  %s\n\n", BuildLayout(after))
+
+	height := defaultRenderHeight(before, *width)
+	fmt.Print(sideBySide(
+		"Before\n"+renderLayout(before, *width, height, *ascii),
+		"After\n"+renderLayout(after, *width, height, *ascii),
+		2,
+	))
+	return nil
+}
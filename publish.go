@@ -0,0 +1,44 @@
+package main
+
+import (
+	"strconv"
+)
+
+const (
+	// Window options published for status formats, so status lines can show
+	// zoom state without running the binary, e.g. #{?@focus-zoom-active,ON,OFF}
+	activeOption  = "@focus-zoom-active"
+	percentOption = "@focus-zoom-effective-percent"
+)
+
+// publishActive records in a window's options whether zoom is on there.
+// Turning it off in global mode sets 0 to override the global 1.
+func publishActive(target string, on, global bool) error {
+	switch {
+	case on:
+		return SetScopedOptionIn("-w", target, activeOption, "1")
+	case global:
+		if err := SetScopedOptionIn("-w", target, activeOption, "0"); err != nil {
+			return err
+		}
+	default:
+		if err := UnsetScopedOptionIn("-w", target, activeOption); err != nil {
+			return err
+		}
+	}
+	return UnsetScopedOptionIn("-w", target, percentOption)
+}
+
+// publishGlobal sets @focus-zoom-active for every window in global mode,
+// including windows not zoomed yet
+func publishGlobal(on bool) error {
+	if on {
+		return SetScopedOption("-gw", activeOption, "1")
+	}
+	return UnsetScopedOption("-gw", activeOption)
+}
+
+// publishPercent records the zoom percentage in effect in the current window
+func publishPercent(percent int) error {
+	return SetScopedOption("-w", percentOption, strconv.Itoa(percent))
+}
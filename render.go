@@ -0,0 +1,243 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+const (
+	// defaultRenderWidth is the diagram width used when --width is not given
+	defaultRenderWidth = 80
+)
+
+// Line directions a diagram cell connects to
+const (
+	lineUp = 1 << iota
+	lineDown
+	lineLeft
+	lineRight
+)
+
+// unicodeGlyphs maps a cell's line directions to a box-drawing character
+var unicodeGlyphs = map[int]rune{
+	lineUp:                                   '│',
+	lineDown:                                 '│',
+	lineUp | lineDown:                        '│',
+	lineLeft:                                 '─',
+	lineRight:                                '─',
+	lineLeft | lineRight:                     '─',
+	lineDown | lineRight:                     '┌',
+	lineDown | lineLeft:                      '┐',
+	lineUp | lineRight:                       '└',
+	lineUp | lineLeft:                        '┘',
+	lineUp | lineDown | lineRight:            '├',
+	lineUp | lineDown | lineLeft:             '┤',
+	lineDown | lineLeft | lineRight:          '┬',
+	lineUp | lineLeft | lineRight:            '┴',
+	lineUp | lineDown | lineLeft | lineRight: '┼',
+}
+
+// RenderLayout draws the layout tree as a box diagram of w columns by h rows,
+// labelling each pane with its ID and size
+func RenderLayout(node *LayoutNode, w, h int) string {
+	return renderLayout(node, w, h, false)
+}
+
+// RenderLayoutASCII is RenderLayout using only ASCII characters
+func RenderLayoutASCII(node *LayoutNode, w, h int) string {
+	return renderLayout(node, w, h, true)
+}
+
+// renderLayout draws the diagram on a grid of line directions, then turns
+// each cell into a glyph so shared borders join up correctly
+func renderLayout(node *LayoutNode, w, h int, ascii bool) string {
+	if node == nil || w < 2 || h < 2 || node.Width <= 0 || node.Height <= 0 {
+		return ""
+	}
+
+	lines := make([][]int, h)
+	labels := make([][]rune, h)
+	for y := range lines {
+		lines[y] = make([]int, w)
+		labels[y] = make([]rune, w)
+	}
+
+	// Map layout coordinates (including the outer border at -1 and Width/Height) onto the grid
+	col := func(x int) int { return clampInt((x-node.X+1)*(w-1)/(node.Width+1), 0, w-1) }
+	row := func(y int) int { return clampInt((y-node.Y+1)*(h-1)/(node.Height+1), 0, h-1) }
+
+	hline := func(y, x0, x1 int) {
+		for x := x0; x <= x1; x++ {
+			if x > x0 {
+				lines[y][x] |= lineLeft
+			}
+			if x < x1 {
+				lines[y][x] |= lineRight
+			}
+		}
+	}
+	vline := func(x, y0, y1 int) {
+		for y := y0; y <= y1; y++ {
+			if y > y0 {
+				lines[y][x] |= lineUp
+			}
+			if y < y1 {
+				lines[y][x] |= lineDown
+			}
+		}
+	}
+
+	for _, leaf := range node.Leaves() {
+		x0, x1 := col(leaf.X-1), col(leaf.X+leaf.Width)
+		y0, y1 := row(leaf.Y-1), row(leaf.Y+leaf.Height)
+		hline(y0, x0, x1)
+		hline(y1, x0, x1)
+		vline(x0, y0, y1)
+		vline(x1, y0, y1)
+
+		// Label the interior: pane ID on the middle row, size below it if there's room
+		innerW, innerH := x1-x0-1, y1-y0-1
+		if innerW <= 0 || innerH <= 0 {
+			continue
+		}
+		text := []string{fmt.Sprintf("%%%d", leaf.PaneID)}
+		if innerH >= 2 {
+			text = append(text, fmt.Sprintf("%dx%d", leaf.Width, leaf.Height))
+		}
+		top := y0 + 1 + (innerH-len(text))/2
+		for i, s := range text {
+			r := []rune(s)
+			if len(r) > innerW {
+				r = r[:innerW]
+			}
+			left := x0 + 1 + (innerW-len(r))/2
+			copy(labels[top+i][left:], r)
+		}
+	}
+
+	var sb strings.Builder
+	for y := range lines {
+		for x, mask := range lines[y] {
+			switch {
+			case labels[y][x] != 0:
+				sb.WriteRune(labels[y][x])
+			case mask == 0:
+				sb.WriteByte(' ')
+			case ascii:
+				sb.WriteRune(asciiGlyph(mask))
+			default:
+				sb.WriteRune(unicodeGlyphs[mask])
+			}
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// clampInt limits v to the range [lo, hi]
+func clampInt(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+// asciiGlyph returns the ASCII character for a cell's line directions
+func asciiGlyph(mask int) rune {
+	vertical := mask&(lineUp|lineDown) != 0
+	horizontal := mask&(lineLeft|lineRight) != 0
+	switch {
+	case vertical && horizontal:
+		return '+'
+	case vertical:
+		return '|'
+	default:
+		return '-'
+	}
+}
+
+// defaultRenderHeight picks a diagram height that keeps the layout's proportions
+func defaultRenderHeight(node *LayoutNode, width int) int {
+	if node.Width <= 0 {
+		return 3
+	}
+	height := (width * node.Height) / node.Width
+	if height < 3 {
+		height = 3
+	}
+	return height
+}
+
+// cmdRender draws the current window's layout, or the one given with --layout
+func cmdRender(args []string) error {
+	fs := flag.NewFlagSet("render", flag.ContinueOnError)
+	layout := fs.String("layout", "", "layout string to render (default: current window)")
+	width := fs.Int("width", defaultRenderWidth, "diagram width in columns")
+	height := fs.Int("height", 0, "diagram height in rows (default: keep proportions)")
+	ascii := fs.Bool("ascii", false, "draw with ASCII characters only")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *layout == "" {
+		current, err := GetWindowLayout()
+		if err != nil {
+			return fmt.Errorf("GetWindowLayout: %w", err)
+		}
+		*layout = current
+	}
+
+	tree, err := ParseLayout(*layout)
+	if err != nil {
+		return fmt.Errorf("ParseLayout: %w", err)
+	}
+
+	if *width < 2 {
+		return fmt.Errorf("invalid width: %d", *width)
+	}
+	if *height == 0 {
+		*height = defaultRenderHeight(tree, *width)
+	}
+	if *height < 2 {
+		return fmt.Errorf("invalid height: %d", *height)
+	}
+
+	fmt.Println(*layout)
+	fmt.Print(renderLayout(tree, *width, *height, *ascii))
+	return nil
+}
+
+// sideBySide places two diagrams next to each other with gap spaces between them
+func sideBySide(left, right string, gap int) string {
+	leftLines := strings.Split(strings.TrimSuffix(left, "\n"), "\n")
+	rightLines := strings.Split(strings.TrimSuffix(right, "\n"), "\n")
+
+	leftWidth := 0
+	for _, line := range leftLines {
+		if n := len([]rune(line)); n > leftWidth {
+			leftWidth = n
+		}
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(leftLines) || i < len(rightLines); i++ {
+		var l, r string
+		if i < len(leftLines) {
+			l = leftLines[i]
+		}
+		if i < len(rightLines) {
+			r = rightLines[i]
+		}
+		sb.WriteString(l)
+		if r != "" {
+			sb.WriteString(strings.Repeat(" ", leftWidth-len([]rune(l))+gap))
+			sb.WriteString(r)
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderLayout_TwoColumns(t *testing.T) {
+	node, err := ParseLayout("1234,199x53,0,0{99x53,0,0,1,99x53,100,0,2}")
+	if err != nil {
+		t.Fatalf("ParseLayout failed: %v", err)
+	}
+
+	expected := strings.Join([]string{
+		"┌─────────┬─────────┐",
+		"│   %1    │   %2    │",
+		"│  99x53  │  99x53  │",
+		"│         │         │",
+		"└─────────┴─────────┘",
+	}, "\n") + "\n"
+
+	got := RenderLayout(node, 21, 5)
+	if got != expected {
+		t.Errorf("RenderLayout mismatch:\ngot:\n%s\nwant:\n%s", got, expected)
+	}
+}
+
+func TestRenderLayoutASCII_NestedSplit(t *testing.T) {
+	node, err := ParseLayout(cortexLayout)
+	if err != nil {
+		t.Fatalf("ParseLayout failed: %v", err)
+	}
+
+	expected := strings.Join([]string{
+		"+-----------+-------------+------------+",
+		"|    %26    |             |            |",
+		"|   84x30   |             |            |",
+		"|           |     %36     |    %42     |",
+		"+-----------+    85x61    |   84x61    |",
+		"|    %41    |             |            |",
+		"|   84x30   |             |            |",
+		"|           |             |            |",
+		"+-----------+-------------+------------+",
+	}, "\n") + "\n"
+
+	got := RenderLayoutASCII(node, 40, 9)
+	if got != expected {
+		t.Errorf("RenderLayoutASCII mismatch:\ngot:\n%s\nwant:\n%s", got, expected)
+	}
+}
+
+func TestRenderLayout_TooSmall(t *testing.T) {
+	node, err := ParseLayout("1234,100x50,0,0,1")
+	if err != nil {
+		t.Fatalf("ParseLayout failed: %v", err)
+	}
+	if got := RenderLayout(node, 1, 1); got != "" {
+		t.Errorf("expected empty diagram for 1x1 canvas, got %q", got)
+	}
+}
+
+func TestRenderLayout_PaneOutsideWindow(t *testing.T) {
+	// Nested panes whose offsets run past the window are drawn at the edge
+	node, err := ParseLayout("0000,120x40,0,0{42x40,0,0,0,77x40,43,0[77x14,61,0,1,77x25,61,15,2]}")
+	if err != nil {
+		t.Fatalf("ParseLayout failed: %v", err)
+	}
+	if got := RenderLayout(node, 80, 20); got == "" {
+		t.Error("expected a diagram")
+	}
+}
+
+func TestSideBySide(t *testing.T) {
+	left := "ab\nabcd\n"
+	right := "x\ny\nz\n"
+
+	expected := "ab    x\nabcd  y\n      z\n"
+	if got := sideBySide(left, right, 2); got != expected {
+		t.Errorf("sideBySide: got %q, want %q", got, expected)
+	}
+}
@@ -0,0 +1,409 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// scaleSizes scales sizes proportionally so they add up to total, keeping each at least 1.
+// Rounding leftovers go to the entries with the largest remainders.
+func scaleSizes(sizes []int, total int) []int {
+	result := make([]int, len(sizes))
+	if len(sizes) == 0 {
+		return result
+	}
+
+	sum := 0
+	for _, size := range sizes {
+		sum += size
+	}
+	if sum <= 0 {
+		// Nothing to be proportional to - split evenly
+		sizes = make([]int, len(sizes))
+		for i := range sizes {
+			sizes[i] = 1
+		}
+		sum = len(sizes)
+	}
+
+	remainders := make([]int, len(sizes))
+	used := 0
+	for i, size := range sizes {
+		result[i] = (size * total) / sum
+		remainders[i] = (size * total) % sum
+		if result[i] < 1 {
+			result[i] = 1
+			remainders[i] = 0
+		}
+		used += result[i]
+	}
+
+	order := make([]int, len(sizes))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return remainders[order[a]] > remainders[order[b]]
+	})
+
+	// Hand out what rounding down left over
+	for i := 0; used < total; i = (i + 1) % len(order) {
+		result[order[i]]++
+		used++
+	}
+
+	// Take back what the minimum of 1 overspent, from the largest entries
+	for used > total {
+		largest := 0
+		for i := range result {
+			if result[i] > result[largest] {
+				largest = i
+			}
+		}
+		if result[largest] <= 1 {
+			break
+		}
+		result[largest]--
+		used--
+	}
+	return result
+}
+
+// fitLayout resizes a tree to width x height at x, y. Children of each split
+// keep their proportions along the split and fill it across, with one cell per border.
+func fitLayout(node *LayoutNode, x, y, width, height int) {
+	node.X, node.Y = x, y
+	node.Width, node.Height = width, height
+
+	if len(node.Children) == 0 {
+		return
+	}
+	borders := len(node.Children) - 1
+
+	switch node.SplitType {
+	case SplitHorizontal:
+		var widths []int
+		for _, child := range node.Children {
+			widths = append(widths, child.Width)
+		}
+		widths = scaleSizes(widths, width-borders)
+		currentX := x
+		for i, child := range node.Children {
+			fitLayout(child, currentX, y, widths[i], height)
+			currentX += widths[i] + 1 // +1 for border
+		}
+	case SplitVertical:
+		var heights []int
+		for _, child := range node.Children {
+			heights = append(heights, child.Height)
+		}
+		heights = scaleSizes(heights, height-borders)
+		currentY := y
+		for i, child := range node.Children {
+			fitLayout(child, x, currentY, width, heights[i])
+			currentY += heights[i] + 1 // +1 for border
+		}
+	}
+}
+
+// pruneLayout returns a copy of the tree without the panes missing from live.
+// Splits left with a single child are replaced by that child, which takes over
+// the split's space. Returns nil if no panes are left.
+func pruneLayout(node *LayoutNode, live map[int]bool) *LayoutNode {
+	if node.SplitType == SplitNone {
+		if !live[node.PaneID] {
+			return nil
+		}
+		return copyLayoutNode(node)
+	}
+
+	result := &LayoutNode{
+		Width:     node.Width,
+		Height:    node.Height,
+		X:         node.X,
+		Y:         node.Y,
+		PaneID:    node.PaneID,
+		SplitType: node.SplitType,
+	}
+	for _, child := range node.Children {
+		pruned := pruneLayout(child, live)
+		if pruned == nil {
+			continue
+		}
+		if pruned.SplitType == result.SplitType {
+			// A collapsed child split the same way as us - adopt its children
+			result.Children = append(result.Children, pruned.Children...)
+			continue
+		}
+		result.Children = append(result.Children, pruned)
+	}
+
+	switch len(result.Children) {
+	case 0:
+		return nil
+	case 1:
+		only := result.Children[0]
+		fitLayout(only, result.X, result.Y, result.Width, result.Height)
+		return only
+	default:
+		return result
+	}
+}
+
+// graftPane adds newPane to tree next to neighbour, on the given side of a split
+// of splitType. The neighbour's space is shared between the two.
+func graftPane(tree *LayoutNode, neighbour, newPane int, splitType SplitType, after bool) *LayoutNode {
+	leaf, ancestors := tree.FindPane(neighbour)
+	if leaf == nil {
+		return tree
+	}
+
+	x, y, width, height := leaf.X, leaf.Y, leaf.Width, leaf.Height
+	added := &LayoutNode{PaneID: newPane, SplitType: SplitNone, Width: width, Height: height}
+	pair := []*LayoutNode{leaf, added}
+	if !after {
+		pair = []*LayoutNode{added, leaf}
+	}
+
+	var parent *LayoutNode
+	if len(ancestors) > 0 {
+		parent = ancestors[len(ancestors)-1]
+	}
+
+	if parent != nil && parent.SplitType == splitType {
+		// Join the existing split as a sibling, halving the neighbour's share
+		if splitType == SplitHorizontal {
+			leaf.Width = width / 2
+			added.Width = width - leaf.Width - 1
+		} else {
+			leaf.Height = height / 2
+			added.Height = height - leaf.Height - 1
+		}
+		idx := indexOfChild(parent, leaf)
+		children := append([]*LayoutNode{}, parent.Children[:idx]...)
+		children = append(children, pair...)
+		parent.Children = append(children, parent.Children[idx+1:]...)
+		return tree
+	}
+
+	// Replace the neighbour with a new split holding both panes
+	split := &LayoutNode{PaneID: -1, SplitType: splitType, Children: pair}
+	fitLayout(split, x, y, width, height)
+	if parent == nil {
+		return split
+	}
+	parent.Children[indexOfChild(parent, leaf)] = split
+	return tree
+}
+
+// indexOfChild returns the position of child in parent's children, or -1
+func indexOfChild(parent, child *LayoutNode) int {
+	for i, c := range parent.Children {
+		if c == child {
+			return i
+		}
+	}
+	return -1
+}
+
+// findNeighbour finds the pane in current that sits closest to paneID and is
+// also in the tree being rebuilt (has[id]). It returns the split the two share
+// in current and whether paneID comes after the neighbour.
+func findNeighbour(current *LayoutNode, paneID int, has map[int]bool) (neighbour int, splitType SplitType, after bool, ok bool) {
+	path, found := current.PanePath(paneID)
+	if !found {
+		return 0, SplitNone, false, false
+	}
+
+	// Look at siblings level by level, starting with the pane's own split
+	for depth := len(path) - 1; depth >= 0; depth-- {
+		parent, _ := current.NodeAt(path[:depth])
+		idx := path[depth]
+		for dist := 1; dist < len(parent.Children); dist++ {
+			if before := idx - dist; before >= 0 {
+				if id, ok := lastPaneIn(parent.Children[before], has); ok {
+					return id, parent.SplitType, true, true
+				}
+			}
+			if next := idx + dist; next < len(parent.Children) {
+				if id, ok := firstPaneIn(parent.Children[next], has); ok {
+					return id, parent.SplitType, false, true
+				}
+			}
+		}
+	}
+	return 0, SplitNone, false, false
+}
+
+// firstPaneIn returns the first pane in layout order that has[id]
+func firstPaneIn(node *LayoutNode, has map[int]bool) (int, bool) {
+	for _, id := range node.paneIDs() {
+		if has[id] {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+// lastPaneIn returns the last pane in layout order that has[id]
+func lastPaneIn(node *LayoutNode, has map[int]bool) (int, bool) {
+	ids := node.paneIDs()
+	for i := len(ids) - 1; i >= 0; i-- {
+		if has[ids[i]] {
+			return ids[i], true
+		}
+	}
+	return 0, false
+}
+
+// mergeSnapshot rebuilds a snapshot for the panes in current: panes that were
+// closed are dropped and their space given to their siblings, and panes created
+// since are placed next to the neighbour they have in current. The result is
+// sized to current.
+func mergeSnapshot(snapshot, current *LayoutNode) (*LayoutNode, error) {
+	live := make(map[int]bool)
+	for _, id := range current.paneIDs() {
+		live[id] = true
+	}
+
+	merged := pruneLayout(snapshot, live)
+	if merged == nil {
+		return nil, fmt.Errorf("no panes in common with snapshot")
+	}
+
+	has := make(map[int]bool)
+	for _, id := range merged.paneIDs() {
+		has[id] = true
+	}
+	for _, id := range current.paneIDs() {
+		if has[id] {
+			continue
+		}
+		neighbour, splitType, after, ok := findNeighbour(current, id, has)
+		if !ok {
+			return nil, fmt.Errorf("no neighbour found for pane %d", id)
+		}
+		merged = graftPane(merged, neighbour, id, splitType, after)
+		has[id] = true
+	}
+
+	// Fill the window as it is now, which may have been resized since the snapshot
+	fitLayout(merged, current.X, current.Y, current.Width, current.Height)
+	return merged, nil
+}
+
+// restoreMode is how a snapshot is brought back given the panes the window has now
+type restoreMode int
+
+const (
+	restoreFull  restoreMode = iota // Same panes as the snapshot - restore it exactly
+	restoreRemap                    // Same number of panes, some replaced - new panes take the closed ones' places
+	restoreMerge                    // Panes added or closed - rebuild the snapshot around the current panes
+	restoreSkip                     // Nothing in common with the snapshot - keep the current layout
+	restoreNone                     // No snapshot was taken - nothing to restore
+)
+
+// String returns the name used in debug logs
+func (m restoreMode) String() string {
+	switch m {
+	case restoreFull:
+		return "full"
+	case restoreRemap:
+		return "remap"
+	case restoreMerge:
+		return "merge"
+	case restoreNone:
+		return "none"
+	default:
+		return "skip"
+	}
+}
+
+// restoreMessage is the message shown when zoom is turned off
+func restoreMessage(mode restoreMode) string {
+	switch mode {
+	case restoreRemap:
+		return "Focus zoom: OFF (layout restored, replaced panes remapped)"
+	case restoreMerge:
+		return "Focus zoom: OFF (layout restored around added/closed panes)"
+	case restoreSkip:
+		return "Focus zoom: OFF (panes changed, layout kept)"
+	default:
+		return "Focus zoom: OFF"
+	}
+}
+
+// planRestore compares the pane IDs in the snapshot with the window's current panes
+func planRestore(snapshot, current *LayoutNode) restoreMode {
+	snapshotIDs := snapshot.paneIDs()
+	currentIDs := current.paneIDs()
+
+	inSnapshot := make(map[int]bool)
+	for _, id := range snapshotIDs {
+		inSnapshot[id] = true
+	}
+	common := 0
+	for _, id := range currentIDs {
+		if inSnapshot[id] {
+			common++
+		}
+	}
+
+	switch {
+	case common == 0:
+		return restoreSkip
+	case len(snapshotIDs) == len(currentIDs) && common == len(currentIDs):
+		return restoreFull
+	case len(snapshotIDs) == len(currentIDs):
+		return restoreRemap
+	default:
+		return restoreMerge
+	}
+}
+
+// RestoreSnapshotForPanes restores the saved layout, adapting it to the panes
+// the window has now. Returns which kind of restore was done.
+func RestoreSnapshotForPanes(state *State) (restoreMode, error) {
+	return RestoreSnapshotForPanesIn("", state)
+}
+
+// RestoreSnapshotForPanesIn is RestoreSnapshotForPanes for a target window
+func RestoreSnapshotForPanesIn(target string, state *State) (restoreMode, error) {
+	if state.Snapshot == "" {
+		return restoreNone, nil
+	}
+	snapshotTree, err := ParseLayout(state.Snapshot)
+	if err != nil {
+		return restoreSkip, err
+	}
+	currentLayout, err := GetWindowLayoutIn(target)
+	if err != nil {
+		return restoreSkip, err
+	}
+	currentTree, err := ParseLayout(currentLayout)
+	if err != nil {
+		return restoreSkip, err
+	}
+
+	mode := planRestore(snapshotTree, currentTree)
+	switch mode {
+	case restoreFull:
+		return mode, RestoreSnapshotIn(target, state)
+
+	case restoreRemap:
+		remapPanes(snapshotTree, currentTree.paneIDs())
+		return mode, RestoreSnapshotIn(target, &State{Snapshot: BuildLayout(snapshotTree)})
+
+	case restoreMerge:
+		merged, err := mergeSnapshot(snapshotTree, currentTree)
+		if err != nil {
+			return restoreSkip, err
+		}
+		layout := BuildLayout(merged)
+		debugf("RestoreSnapshotForPanes: merged %s", layout)
+		return mode, RestoreSnapshotIn(target, &State{Snapshot: layout})
+
+	default:
+		return mode, nil
+	}
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestScaleSizes(t *testing.T) {
+	tests := []struct {
+		name     string
+		sizes    []int
+		total    int
+		expected []int
+	}{
+		{"unchanged", []int{84, 85, 84}, 253, []int{84, 85, 84}},
+		{"grow", []int{50, 50}, 120, []int{60, 60}},
+		{"largest remainder", []int{1, 1, 1}, 10, []int{4, 3, 3}},
+		{"minimum one", []int{100, 1}, 10, []int{9, 1}},
+		{"all zero", []int{0, 0}, 4, []int{2, 2}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := scaleSizes(tc.sizes, tc.total)
+			for i := range tc.expected {
+				if got[i] != tc.expected[i] {
+					t.Fatalf("scaleSizes(%v, %d) = %v, want %v", tc.sizes, tc.total, got, tc.expected)
+				}
+			}
+		})
+	}
+}
+
+func TestFitLayout_SameSizeIsUnchanged(t *testing.T) {
+	node, err := ParseLayout(cortexLayout)
+	if err != nil {
+		t.Fatalf("ParseLayout failed: %v", err)
+	}
+
+	fitLayout(node, 0, 0, 255, 61)
+	if got := BuildLayout(node); got != cortexLayout {
+		t.Errorf("fitLayout changed layout: got %s, want %s", got, cortexLayout)
+	}
+}
+
+func TestMergeSnapshot(t *testing.T) {
+	// Two columns: [0 over 3] and [1 over 2]
+	snapshot := "94cb,120x40,0,0{60x40,0,0[60x20,0,0,0,60x19,0,21,3],59x40,61,0[59x20,61,0,1,59x19,61,21,2]}"
+
+	tests := []struct {
+		name     string
+		current  string
+		expected string
+	}{
+		{
+			name:     "closed pane gives space to sibling",
+			current:  "21fc,120x40,0,0{77x40,0,0[77x14,0,0,0,77x25,0,15,3],42x40,78,0,2}",
+			expected: "151a,120x40,0,0{60x40,0,0[60x20,0,0,0,60x19,0,21,3],59x40,61,0,2}",
+		},
+		{
+			name:     "new pane is placed next to its neighbour",
+			current:  "4dd2,120x40,0,0{77x40,0,0[77x14,0,0,0,77x25,0,15,3],42x40,78,0[42x20,78,0,1,42x19,78,21{21x19,78,21,2,20x19,100,21,4}]}",
+			expected: "cd79,120x40,0,0{60x40,0,0[60x20,0,0,0,60x19,0,21,3],59x40,61,0[59x20,61,0,1,59x19,61,21{29x19,61,21,2,29x19,91,21,4}]}",
+		},
+		{
+			name:     "new pane joins a split of the same direction",
+			current:  "0000,120x40,0,0{60x40,0,0[60x20,0,0,0,60x19,0,21,3],59x40,61,0[59x13,61,0,1,59x12,61,14,5,59x12,61,27,2]}",
+			expected: "b43a,120x40,0,0{60x40,0,0[60x20,0,0,0,60x19,0,21,3],59x40,61,0[59x10,61,0,1,59x9,61,11,5,59x19,61,21,2]}",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			snapshotTree, err := ParseLayout(snapshot)
+			if err != nil {
+				t.Fatalf("ParseLayout snapshot failed: %v", err)
+			}
+			currentTree, err := ParseLayout(tc.current)
+			if err != nil {
+				t.Fatalf("ParseLayout current failed: %v", err)
+			}
+
+			merged, err := mergeSnapshot(snapshotTree, currentTree)
+			if err != nil {
+				t.Fatalf("mergeSnapshot failed: %v", err)
+			}
+			if got := BuildLayout(merged); got != tc.expected {
+				t.Errorf("mergeSnapshot:\ngot  %s\nwant %s", got, tc.expected)
+			}
+			if err := validateLayout(merged); err != nil {
+				t.Errorf("merged layout is invalid: %v", err)
+			}
+		})
+	}
+}
+
+func TestMergeSnapshot_NothingInCommon(t *testing.T) {
+	snapshotTree, _ := ParseLayout("1234,199x53,0,0{99x53,0,0,1,99x53,100,0,2}")
+	currentTree, _ := ParseLayout("1234,199x53,0,0{99x53,0,0,7,99x53,100,0,8}")
+
+	if _, err := mergeSnapshot(snapshotTree, currentTree); err == nil {
+		t.Error("expected error when snapshot shares no panes with the window")
+	}
+}
+
+func TestScaleLayout(t *testing.T) {
+	node, err := ParseLayout(cortexLayout)
+	if err != nil {
+		t.Fatalf("ParseLayout failed: %v", err)
+	}
+
+	// Shrink 255x61 to roughly half
+	scaleLayout(node, 127, 30)
+
+	if err := validateLayout(node); err != nil {
+		t.Fatalf("scaled layout is invalid: %v", err)
+	}
+	if node.Width != 127 || node.Height != 30 {
+		t.Errorf("root size: got %dx%d, want 127x30", node.Width, node.Height)
+	}
+
+	// Columns were 84/85/84 - they should stay even, and the two rows in col0 too
+	col0, col1, col2 := node.Children[0], node.Children[1], node.Children[2]
+	if abs(col0.Width-col1.Width) > 1 || abs(col1.Width-col2.Width) > 1 {
+		t.Errorf("column widths not proportional: %d, %d, %d", col0.Width, col1.Width, col2.Width)
+	}
+	if abs(col0.Children[0].Height-col0.Children[1].Height) > 1 {
+		t.Errorf("row heights not proportional: %d, %d", col0.Children[0].Height, col0.Children[1].Height)
+	}
+	if col2.X != col1.X+col1.Width+1 {
+		t.Errorf("col2 x: got %d, want %d", col2.X, col1.X+col1.Width+1)
+	}
+}
+
+func TestPlanRestore(t *testing.T) {
+	snapshot := "94cb,120x40,0,0{60x40,0,0[60x20,0,0,0,60x19,0,21,3],59x40,61,0[59x20,61,0,1,59x19,61,21,2]}"
+
+	tests := []struct {
+		name     string
+		current  string
+		expected restoreMode
+	}{
+		{
+			name:     "same panes",
+			current:  "5233,120x40,0,0{77x40,0,0[77x14,0,0,0,77x25,0,15,3],42x40,78,0[42x20,78,0,1,42x19,78,21,2]}",
+			expected: restoreFull,
+		},
+		{
+			name:     "pane replaced",
+			current:  "4cea,120x40,0,0{77x40,0,0[77x14,0,0,0,77x25,0,15,3],21x40,78,0,2,20x40,100,0,4}",
+			expected: restoreRemap,
+		},
+		{
+			name:     "pane closed",
+			current:  "21fc,120x40,0,0{77x40,0,0[77x14,0,0,0,77x25,0,15,3],42x40,78,0,2}",
+			expected: restoreMerge,
+		},
+		{
+			name:     "all panes replaced",
+			current:  "1234,120x40,0,0{60x40,0,0,8,59x40,61,0,9}",
+			expected: restoreSkip,
+		},
+		{
+			name:     "all panes replaced, same count",
+			current:  "94cb,120x40,0,0{60x40,0,0[60x20,0,0,4,60x19,0,21,7],59x40,61,0[59x20,61,0,5,59x19,61,21,6]}",
+			expected: restoreSkip,
+		},
+	}
+
+	snapshotTree, err := ParseLayout(snapshot)
+	if err != nil {
+		t.Fatalf("ParseLayout snapshot failed: %v", err)
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			currentTree, err := ParseLayout(tc.current)
+			if err != nil {
+				t.Fatalf("ParseLayout current failed: %v", err)
+			}
+			if got := planRestore(snapshotTree, currentTree); got != tc.expected {
+				t.Errorf("planRestore: got %s, want %s", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestRestoreSnapshotForPanes_NoSnapshot(t *testing.T) {
+	mode, err := RestoreSnapshotForPanesIn("@1", &State{})
+	if err != nil {
+		t.Fatalf("RestoreSnapshotForPanesIn failed: %v", err)
+	}
+	if mode != restoreNone {
+		t.Errorf("mode: got %s, want %s", mode, restoreNone)
+	}
+	if msg := restoreMessage(mode); msg != "Focus zoom: OFF" {
+		t.Errorf("message: got %q, want %q", msg, "Focus zoom: OFF")
+	}
+}
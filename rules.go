@@ -0,0 +1,196 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+const (
+	rulesFile = "rules.json"
+)
+
+// zoomAxis limits which splits are zoomed
+type zoomAxis int
+
+const (
+	axisBoth       zoomAxis = iota // Zoom width and height
+	axisHorizontal                 // Only zoom width (side-by-side splits)
+	axisVertical                   // Only zoom height (top-to-bottom splits)
+)
+
+// Rule sets zoom behaviour for panes whose command or title matches.
+// A rule needs at least one of Command or Title; if both are set, both must match.
+type Rule struct {
+	Command string `json:"command,omitempty"` // regexp for #{pane_current_command}
+	Title   string `json:"title,omitempty"`   // regexp for #{pane_title}
+	Percent int    `json:"percent,omitempty"` // zoom percentage when the pane is focused
+	Skip    bool   `json:"skip,omitempty"`    // focusing the pane doesn't zoom
+	Pinned  bool   `json:"pinned,omitempty"`  // the pane keeps its size
+	Axis    string `json:"axis,omitempty"`    // "both", "horizontal" or "vertical"
+
+	command *regexp.Regexp
+	title   *regexp.Regexp
+	axis    zoomAxis
+}
+
+// rulesConfig is the layout of rules.json
+type rulesConfig struct {
+	Rules []*Rule `json:"rules"`
+}
+
+// paneRule is the combined effect of every rule matching a pane
+type paneRule struct {
+	Percent int // 0 if no rule sets it
+	Skip    bool
+	Pinned  bool
+	Axis    zoomAxis
+}
+
+// rulesFilePath returns the full path to the rules file
+func rulesFilePath() string {
+	return filepath.Join(configDir(), rulesFile)
+}
+
+// LoadRules reads and compiles the rules file. A missing file means no rules.
+func LoadRules() ([]*Rule, error) {
+	data, err := os.ReadFile(rulesFilePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return parseRules(data)
+}
+
+// parseRules decodes and compiles rules
+func parseRules(data []byte) ([]*Rule, error) {
+	var config rulesConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("%s: %w", rulesFile, err)
+	}
+
+	for i, rule := range config.Rules {
+		if rule.Command == "" && rule.Title == "" {
+			return nil, fmt.Errorf("%s: rule %d has no command or title", rulesFile, i+1)
+		}
+		if rule.Command != "" {
+			re, err := regexp.Compile(rule.Command)
+			if err != nil {
+				return nil, fmt.Errorf("%s: rule %d command: %w", rulesFile, i+1, err)
+			}
+			rule.command = re
+		}
+		if rule.Title != "" {
+			re, err := regexp.Compile(rule.Title)
+			if err != nil {
+				return nil, fmt.Errorf("%s: rule %d title: %w", rulesFile, i+1, err)
+			}
+			rule.title = re
+		}
+		if rule.Percent != 0 && !validZoomPercent(rule.Percent) {
+			return nil, fmt.Errorf("%s: rule %d percent must be between %d and %d",
+				rulesFile, i+1, MinZoomPercent, MaxZoomPercent)
+		}
+
+		switch rule.Axis {
+		case "", "both":
+			rule.axis = axisBoth
+		case "horizontal":
+			rule.axis = axisHorizontal
+		case "vertical":
+			rule.axis = axisVertical
+		default:
+			return nil, fmt.Errorf("%s: rule %d has unknown axis %q", rulesFile, i+1, rule.Axis)
+		}
+	}
+	return config.Rules, nil
+}
+
+// matches reports whether the rule applies to a pane
+func (r *Rule) matches(pane PaneInfo) bool {
+	if r.command != nil && !r.command.MatchString(pane.CurrentCommand) {
+		return false
+	}
+	if r.title != nil && !r.title.MatchString(pane.Title) {
+		return false
+	}
+	return r.command != nil || r.title != nil
+}
+
+// matchRules combines the rules matching a pane. Rules are applied in order,
+// so a later rule's percent and axis override an earlier one's.
+func matchRules(rules []*Rule, pane PaneInfo) paneRule {
+	var result paneRule
+	for _, rule := range rules {
+		if !rule.matches(pane) {
+			continue
+		}
+		if rule.Percent != 0 {
+			result.Percent = rule.Percent
+		}
+		if rule.Axis != "" {
+			result.Axis = rule.axis
+		}
+		result.Skip = result.Skip || rule.Skip
+		result.Pinned = result.Pinned || rule.Pinned
+	}
+	return result
+}
+
+// zoomSettings works out how to zoom on activePaneID from the window's panes,
+// the ignore patterns and the rules. skip is true if the pane shouldn't zoom.
+func zoomSettings(panes []PaneInfo, activePaneID int, patterns []string, rules []*Rule) (opts zoomOptions, skip bool) {
+	opts.Pinned = pinnedPanes(panes, patterns)
+
+	for _, pane := range panes {
+		id, err := parsePaneID(pane.ID)
+		if err != nil {
+			continue
+		}
+		rule := matchRules(rules, pane)
+		if rule.Pinned {
+			opts.Pinned[id] = true
+		}
+		if id != activePaneID {
+			continue
+		}
+		opts.Percent = rule.Percent
+		opts.Axis = rule.Axis
+		skip = rule.Skip
+	}
+
+	// Pinned and ignored panes keep their size, so focusing one doesn't zoom
+	skip = skip || opts.Pinned[activePaneID]
+	return opts, skip
+}
+
+// GetZoomSettings returns the zoom options for activePaneID in its window.
+// Without a rule setting the percentage, @focus-zoom-percent is used; a pane
+// maximised with the max command always gets MaxZoomPercent.
+func GetZoomSettings(activePaneID int) (zoomOptions, bool, error) {
+	paneID := fmt.Sprintf("%%%d", activePaneID)
+	panes, err := GetPanesIn(paneID)
+	if err != nil {
+		return zoomOptions{}, false, err
+	}
+	// A broken rules file shouldn't stop zooming; carry on without rules
+	rules, err := LoadRules()
+	if err != nil {
+		debugf("Ignoring rules: %v", err)
+		rules = nil
+	}
+
+	opts, skip := zoomSettings(panes, activePaneID, GetIgnoreCommands(paneID), rules)
+	if opts.Percent == 0 {
+		opts.Percent = GetZoomPercent(paneID)
+	}
+	// A pane maximised with the max command gets the largest zoom until focus moves
+	if GetMaxPane(paneID) == paneID {
+		opts.Percent = MaxZoomPercent
+	}
+	return opts, skip, nil
+}
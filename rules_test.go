@@ -0,0 +1,146 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseRules(t *testing.T) {
+	rules, err := parseRules([]byte(`{"rules": [
+		{"command": "^n?vim$", "percent": 80},
+		{"title": "logs", "skip": true, "axis": "vertical"}
+	]}`))
+	if err != nil {
+		t.Fatalf("parseRules: %v", err)
+	}
+	if len(rules) != 2 {
+		t.Fatalf("got %d rules, want 2", len(rules))
+	}
+	if rules[1].axis != axisVertical {
+		t.Errorf("rule 2 axis = %v, want vertical", rules[1].axis)
+	}
+
+	invalid := []string{
+		`{"rules": [{"percent": 80}]}`,
+		`{"rules": [{"command": "(", "percent": 80}]}`,
+		`{"rules": [{"command": "vim", "percent": 100}]}`,
+		`{"rules": [{"command": "vim", "axis": "diagonal"}]}`,
+		`not json`,
+	}
+	for _, data := range invalid {
+		if _, err := parseRules([]byte(data)); err == nil {
+			t.Errorf("parseRules(%s): expected error", data)
+		}
+	}
+}
+
+func TestMatchRules(t *testing.T) {
+	rules, err := parseRules([]byte(`{"rules": [
+		{"command": "vim", "percent": 70},
+		{"command": "^nvim$", "title": "notes", "percent": 85, "axis": "horizontal"},
+		{"title": "^logs", "skip": true},
+		{"command": "tail", "pinned": true}
+	]}`))
+	if err != nil {
+		t.Fatalf("parseRules: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		pane     PaneInfo
+		expected paneRule
+	}{
+		{"command only", PaneInfo{CurrentCommand: "nvim", Title: "main.go"}, paneRule{Percent: 70}},
+		{"later rule overrides", PaneInfo{CurrentCommand: "nvim", Title: "notes"}, paneRule{Percent: 85, Axis: axisHorizontal}},
+		{"title", PaneInfo{CurrentCommand: "tail", Title: "logs: app"}, paneRule{Skip: true, Pinned: true}},
+		{"no match", PaneInfo{CurrentCommand: "zsh", Title: "zsh"}, paneRule{}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := matchRules(rules, tc.pane); got != tc.expected {
+				t.Errorf("matchRules(%+v) = %+v, want %+v", tc.pane, got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestZoomSettings(t *testing.T) {
+	rules, err := parseRules([]byte(`{"rules": [
+		{"command": "nvim", "percent": 80, "axis": "vertical"},
+		{"command": "htop", "pinned": true}
+	]}`))
+	if err != nil {
+		t.Fatalf("parseRules: %v", err)
+	}
+	panes := []PaneInfo{
+		{ID: "%26", CurrentCommand: "nvim"},
+		{ID: "%41", CurrentCommand: "htop"},
+		{ID: "%42", CurrentCommand: "tail"},
+		{ID: "%43", CurrentCommand: "zsh"},
+	}
+
+	opts, skip := zoomSettings(panes, 26, []string{"tail"}, rules)
+	if skip {
+		t.Error("nvim pane: expected zoom")
+	}
+	if opts.Percent != 80 || opts.Axis != axisVertical {
+		t.Errorf("nvim pane: got percent %d axis %v, want 80 vertical", opts.Percent, opts.Axis)
+	}
+	if len(opts.Pinned) != 2 || !opts.Pinned[41] || !opts.Pinned[42] {
+		t.Errorf("pinned: got %v, want 41 and 42", opts.Pinned)
+	}
+
+	if opts, _ := zoomSettings(panes, 43, nil, rules); opts.Percent != 0 {
+		t.Errorf("zsh pane: got percent %d, want 0 (use the option)", opts.Percent)
+	}
+	if _, skip := zoomSettings(panes, 41, nil, rules); !skip {
+		t.Error("htop pane: expected pinned pane to skip zoom")
+	}
+}
+
+func TestZoomLayoutAxis(t *testing.T) {
+	// %1 on the left, %2 above %3 on the right
+	node, err := ParseLayout("0000,100x40,0,0{50x40,0,0,1,49x40,51,0[49x20,51,0,2,49x19,51,21,3]}")
+	if err != nil {
+		t.Fatalf("ParseLayout: %v", err)
+	}
+
+	vertical := zoomLayout(node, 2, zoomOptions{Percent: 80, Axis: axisVertical})
+	right := vertical.Children[1]
+	if right.Width != 49 {
+		t.Errorf("vertical axis changed width: got %d, want 49", right.Width)
+	}
+	if right.Children[0].Height <= 20 {
+		t.Errorf("vertical axis didn't grow height: got %d", right.Children[0].Height)
+	}
+
+	horizontal := zoomLayout(node, 2, zoomOptions{Percent: 80, Axis: axisHorizontal})
+	right = horizontal.Children[1]
+	if right.Width <= 49 {
+		t.Errorf("horizontal axis didn't grow width: got %d", right.Width)
+	}
+	if right.Children[0].Height != 20 {
+		t.Errorf("horizontal axis changed height: got %d, want 20", right.Children[0].Height)
+	}
+}
+
+func TestGetZoomSettingsIgnoresBrokenRules(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("FOCUS_ZOOM_CONFIG_DIR", dir)
+	if err := os.WriteFile(filepath.Join(dir, rulesFile), []byte(`{"rules": [`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fake := newFakeTmux(t)
+	fake.panes = "%0:0:100:50:0:0:1::vim:\n%1:1:99:50:101:0:0::zsh:"
+	fake.set("global", "@focus-zoom-percent", "70")
+
+	opts, skip, err := GetZoomSettings(0)
+	if err != nil {
+		t.Fatalf("GetZoomSettings: %v", err)
+	}
+	if skip || opts.Percent != 70 {
+		t.Errorf("expected 70%% without rules, got %+v skip=%v", opts, skip)
+	}
+}
@@ -23,12 +23,63 @@ func configDir() string {
 	return filepath.Join(home, defaultConfigDir)
 }
 
-// State represents the focus-zoom state for a session/window
+// State represents the focus-zoom state for a session/window.
+// The top-level fields are the window toggled by hand; Windows holds windows
+// enabled by @focus-zoom-auto or global mode.
 type State struct {
 	Enabled  bool   `json:"enabled"`
 	Session  string `json:"session"`
 	Window   string `json:"window"`
 	Snapshot string `json:"snapshot"`
+
+	Global  bool                    `json:"global,omitempty"`  // on in every window, see toggle --global
+	Windows map[string]*WindowState `json:"windows,omitempty"` // keyed by window ID
+}
+
+// WindowState is the state of a window enabled automatically or by global mode
+type WindowState struct {
+	Session  string `json:"session"`
+	Window   string `json:"window"`
+	Snapshot string `json:"snapshot,omitempty"`
+	Disabled bool   `json:"disabled,omitempty"` // toggled off by hand, so it isn't auto-enabled again
+}
+
+// ForWindow returns the state zooming a window, or nil if zoom is off there
+func (s *State) ForWindow(ref WindowRef) *State {
+	if s.Enabled && s.Session == ref.Session && s.Window == ref.Window {
+		return s
+	}
+	ws, ok := s.Windows[ref.ID]
+	if !ok {
+		if s.Global {
+			// Global mode: on, but the snapshot isn't captured until the window is zoomed
+			return &State{Enabled: true, Session: ref.Session, Window: ref.Window}
+		}
+		return nil
+	}
+	if ws.Disabled {
+		return nil
+	}
+	// Use the window's current name and index in case it was moved or renamed
+	return &State{Enabled: true, Session: ref.Session, Window: ref.Window, Snapshot: ws.Snapshot}
+}
+
+// enabledAnywhere reports whether zoom is on in any window
+func (s *State) enabledAnywhere() bool {
+	if s.Enabled || s.Global {
+		return true
+	}
+	for _, ws := range s.Windows {
+		if !ws.Disabled {
+			return true
+		}
+	}
+	return false
+}
+
+// isEmpty reports whether the state has nothing worth keeping on disk
+func (s *State) isEmpty() bool {
+	return !s.Enabled && !s.Global && len(s.Windows) == 0
 }
 
 // stateFilePath returns the full path to the state file
@@ -83,6 +134,14 @@ func SaveState(state *State) error {
 	return os.WriteFile(path, data, 0644)
 }
 
+// SaveOrClearState writes the state to disk, or removes the file if the state is empty
+func SaveOrClearState(state *State) error {
+	if state.isEmpty() {
+		return ClearState()
+	}
+	return SaveState(state)
+}
+
 // ClearState removes the state file
 func ClearState() error {
 	path, err := stateFilePath()
@@ -95,3 +95,55 @@ func TestClearState(t *testing.T) {
 		t.Error("Expected disabled state after clear")
 	}
 }
+
+func TestStateForWindow(t *testing.T) {
+	state := &State{
+		Enabled:  true,
+		Session:  "main",
+		Window:   "1",
+		Snapshot: "manual",
+		Windows: map[string]*WindowState{
+			"@3": {Session: "main", Window: "2", Snapshot: "auto"},
+			"@4": {Session: "main", Window: "3", Disabled: true},
+		},
+	}
+
+	if got := state.ForWindow(WindowRef{Session: "main", Window: "1", ID: "@1"}); got != state {
+		t.Errorf("manual window: got %+v, want the top-level state", got)
+	}
+
+	// Auto-enabled window that was moved to another index
+	got := state.ForWindow(WindowRef{Session: "main", Window: "5", ID: "@3"})
+	if got == nil || got.Snapshot != "auto" || got.Window != "5" {
+		t.Errorf("auto window: got %+v, want snapshot auto in window 5", got)
+	}
+
+	if got := state.ForWindow(WindowRef{Session: "main", Window: "3", ID: "@4"}); got != nil {
+		t.Errorf("disabled window: got %+v, want nil", got)
+	}
+	if got := state.ForWindow(WindowRef{Session: "other", Window: "1", ID: "@9"}); got != nil {
+		t.Errorf("unknown window: got %+v, want nil", got)
+	}
+}
+
+func TestStateForWindowGlobal(t *testing.T) {
+	state := &State{
+		Global: true,
+		Windows: map[string]*WindowState{
+			"@3": {Session: "main", Window: "2", Snapshot: "captured"},
+			"@4": {Session: "main", Window: "3", Disabled: true},
+		},
+	}
+
+	// Not captured yet: on, without a snapshot
+	got := state.ForWindow(WindowRef{Session: "other", Window: "1", ID: "@9"})
+	if got == nil || got.Snapshot != "" {
+		t.Errorf("new window: got %+v, want enabled without snapshot", got)
+	}
+	if got := state.ForWindow(WindowRef{Session: "main", Window: "2", ID: "@3"}); got == nil || got.Snapshot != "captured" {
+		t.Errorf("captured window: got %+v, want snapshot captured", got)
+	}
+	if got := state.ForWindow(WindowRef{Session: "main", Window: "3", ID: "@4"}); got != nil {
+		t.Errorf("disabled window: got %+v, want nil", got)
+	}
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Status bar colors (Catppuccin Mocha yellow)
+	statusColor = "#f9e2af"
+	zoomIcon    = "󰍉"
+
+	// Defaults for @focus-zoom-status-format and the on/off styles
+	defaultStatusFormat = zoomIcon + " {state}"
+	defaultStatusStyle  = "fg=" + statusColor
+
+	statusFormatOption   = "@focus-zoom-status-format"
+	statusStyleOnOption  = "@focus-zoom-status-style-on"
+	statusStyleOffOption = "@focus-zoom-status-style-off"
+)
+
+// statusFormats is what status reads in a single tmux call, since it runs for
+// every client on each status-interval: the window, its active pane and the
+// options the output depends on
+var statusFormats = []string{
+	"session_name", "window_index", "window_id", "pane_id",
+	"@focus-zoom-percent", "@focus-zoom-presets", maxPaneOption,
+	statusFormatOption, statusStyleOnOption, statusStyleOffOption,
+}
+
+// statusInfo is what the status format placeholders expand to
+type statusInfo struct {
+	On      bool
+	State   string // ON or OFF, or the preset percentage after cycle
+	Percent int    // zoom percentage for the active pane
+	Mode    string // off, manual, auto, global or max
+	Window  string // session:window
+
+	Ref        WindowRef
+	ActivePane string // e.g. "%3"
+	Snapshot   string // layout restored on toggle off, if captured
+
+	format string // @focus-zoom-status-format
+	style  string // @focus-zoom-status-style-on or -off, depending on On
+}
+
+// statusJSON is the output of status --json. Field names are stable.
+type statusJSON struct {
+	Enabled       bool   `json:"enabled"`
+	Session       string `json:"session"`
+	Window        string `json:"window"`
+	WindowID      string `json:"window_id"`
+	Mode          string `json:"mode"`
+	Percent       int    `json:"percent"`
+	ActivePane    string `json:"active_pane"`
+	SnapshotPanes int    `json:"snapshot_panes"`
+	CanRestore    bool   `json:"can_restore"`
+}
+
+// formatStatus expands {state}, {percent}, {mode} and {window} in a format
+func formatStatus(format string, info statusInfo) string {
+	return strings.NewReplacer(
+		"{state}", info.State,
+		"{percent}", strconv.Itoa(info.Percent),
+		"{mode}", info.Mode,
+		"{window}", info.Window,
+	).Replace(format)
+}
+
+// windowMode describes how zoom is on in a window: manual (toggled by hand),
+// global, auto or max (maxed is set when the active pane is maximised), or off
+func windowMode(state *State, ref WindowRef, maxed bool) string {
+	windowState := state.ForWindow(ref)
+	switch {
+	case windowState == nil:
+		return "off"
+	case maxed:
+		return "max"
+	case windowState == state:
+		return "manual"
+	case state.Global:
+		return "global"
+	default:
+		return "auto"
+	}
+}
+
+// effectivePercent returns the zoom percentage a pane gets when active, taking
+// rules and max mode into account
+func effectivePercent(pane string) int {
+	paneID, err := parsePaneID(pane)
+	if err != nil {
+		return GetZoomPercent(pane)
+	}
+	opts, _, err := GetZoomSettings(paneID)
+	if err != nil {
+		return GetZoomPercent(pane)
+	}
+	return opts.Percent
+}
+
+// windowStatus collects the status of a target window, or the current window
+// if target is empty. Zoom settings and rules are only read while zoom is on.
+func windowStatus(state *State, target string) statusInfo {
+	info := statusInfo{State: "OFF", Mode: "off"}
+	values, err := GetFormats(target, statusFormats...)
+	if err != nil {
+		return info
+	}
+	ref := WindowRef{Session: values[0], Window: values[1], ID: values[2]}
+	pane := values[3]
+	percent, presets, maxPane := values[4], values[5], values[6]
+
+	info.Ref = ref
+	info.ActivePane = pane
+	info.Window = ref.Session + ":" + ref.Window
+	info.format = values[7]
+	info.style = values[9]
+	if windowState := state.ForWindow(ref); windowState != nil {
+		info.Snapshot = windowState.Snapshot
+	}
+	info.Mode = windowMode(state, ref, pane != "" && maxPane == pane)
+	info.On = info.Mode != "off"
+	if !info.On {
+		info.Percent = DefaultZoomPercent
+		if n, err := strconv.Atoi(percent); err == nil && validZoomPercent(n) {
+			info.Percent = n
+		}
+		return info
+	}
+
+	info.State = "ON"
+	info.style = values[8]
+	info.Percent = effectivePercent(pane)
+	// Once the window is cycled to a preset, show which one is in use
+	if value, err := GetScopedOptionIn("-w", ref.ID, "@focus-zoom-percent"); err == nil {
+		if preset := windowPreset(value, presetsOrDefault(presets)); preset != "" {
+			info.State = preset + "%"
+		}
+	}
+	return info
+}
+
+// cmdStatus outputs the status for the tmux status bar, formatted with
+// @focus-zoom-status-format and styled with @focus-zoom-status-style-on/off.
+// --format prints a plain format without tmux styles for other consumers.
+// The status line passes its own window as `status #{session_id} #{window_id}`,
+// since the job's current window may belong to another client.
+func cmdStatus(args []string) error {
+	fs := flag.NewFlagSet("status", flag.ContinueOnError)
+	format := fs.String("format", "", "plain output format, e.g. '{state} {percent}' (no tmux styles)")
+	asJSON := fs.Bool("json", false, "print the status as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	target, err := statusTarget(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	state, err := LoadState()
+	if err != nil {
+		state = &State{}
+	}
+	info := windowStatus(state, target)
+
+	if *asJSON {
+		data, err := json.MarshalIndent(newStatusJSON(info), "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	if *format != "" {
+		fmt.Println(formatStatus(*format, info))
+		return nil
+	}
+
+	statusFormat := info.format
+	if statusFormat == "" {
+		statusFormat = defaultStatusFormat
+	}
+	style := info.style
+	if style == "" {
+		style = defaultStatusStyle
+	}
+
+	fmt.Printf("#[%s]%s#[default] ", style, formatStatus(statusFormat, info))
+	return nil
+}
+
+// newStatusJSON builds the status --json output, checking the snapshot
+// against the window's current panes
+func newStatusJSON(info statusInfo) statusJSON {
+	out := statusJSON{
+		Enabled:    info.On,
+		Session:    info.Ref.Session,
+		Window:     info.Ref.Window,
+		WindowID:   info.Ref.ID,
+		Mode:       info.Mode,
+		Percent:    info.Percent,
+		ActivePane: info.ActivePane,
+	}
+	if info.Snapshot == "" {
+		return out
+	}
+	snapshotTree, err := ParseLayout(info.Snapshot)
+	if err != nil {
+		return out
+	}
+	out.SnapshotPanes = len(snapshotTree.Leaves())
+
+	currentLayout, err := GetWindowLayoutIn(info.Ref.ID)
+	if err != nil {
+		return out
+	}
+	currentTree, err := ParseLayout(currentLayout)
+	if err != nil {
+		return out
+	}
+	out.CanRestore = planRestore(snapshotTree, currentTree) != restoreSkip
+	return out
+}
+
+// statusTarget builds the tmux target for status arguments: a session and
+// window ID as passed by the status line, a single target, or none for the
+// current window
+func statusTarget(args []string) (string, error) {
+	switch len(args) {
+	case 0:
+		return "", nil
+	case 1:
+		return args[0], nil
+	case 2:
+		return args[0] + ":" + args[1], nil
+	default:
+		return "", fmt.Errorf("usage: tmux-focus-zoom status [--format F | --json] [session_id window_id]")
+	}
+}
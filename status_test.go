@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestFormatStatus(t *testing.T) {
+	info := statusInfo{On: true, State: "ON", Percent: 65, Mode: "manual", Window: "main:1"}
+
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{defaultStatusFormat, zoomIcon + " ON"},
+		{"{state} {percent}% [{mode}] {window}", "ON 65% [manual] main:1"},
+		{"zoom", "zoom"},
+	}
+
+	for _, tc := range tests {
+		if got := formatStatus(tc.format, info); got != tc.expected {
+			t.Errorf("formatStatus(%q) = %q, want %q", tc.format, got, tc.expected)
+		}
+	}
+}
+
+func TestWindowMode(t *testing.T) {
+	state := &State{
+		Enabled: true,
+		Session: "main",
+		Window:  "1",
+		Windows: map[string]*WindowState{
+			"@2": {Session: "main", Window: "2", Snapshot: "auto"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		ref      WindowRef
+		expected string
+	}{
+		{"manual", WindowRef{Session: "main", Window: "1", ID: "@1"}, "manual"},
+		{"auto", WindowRef{Session: "main", Window: "2", ID: "@2"}, "auto"},
+		{"off", WindowRef{Session: "main", Window: "3", ID: "@3"}, "off"},
+	}
+	for _, tc := range tests {
+		if got := windowMode(state, tc.ref, false); got != tc.expected {
+			t.Errorf("%s: windowMode = %q, want %q", tc.name, got, tc.expected)
+		}
+	}
+
+	if got := windowMode(state, tests[0].ref, true); got != "max" {
+		t.Errorf("max: windowMode = %q, want max", got)
+	}
+
+	state.Global = true
+	if got := windowMode(state, WindowRef{Session: "main", Window: "3", ID: "@3"}, false); got != "global" {
+		t.Errorf("global: windowMode = %q, want global", got)
+	}
+}
+
+func TestStatusJSONFieldNames(t *testing.T) {
+	data, err := json.Marshal(statusJSON{})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	expected := `{"enabled":false,"session":"","window":"","window_id":"","mode":"","percent":0,` +
+		`"active_pane":"","snapshot_panes":0,"can_restore":false}`
+	if string(data) != expected {
+		t.Errorf("status JSON changed:\ngot  %s\nwant %s", data, expected)
+	}
+}
+
+func TestStatusTarget(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{nil, ""},
+		{[]string{"@3"}, "@3"},
+		{[]string{"$1", "@3"}, "$1:@3"},
+	}
+	for _, tc := range tests {
+		got, err := statusTarget(tc.args)
+		if err != nil || got != tc.expected {
+			t.Errorf("statusTarget(%q) = %q, %v; want %q", tc.args, got, err, tc.expected)
+		}
+	}
+
+	if _, err := statusTarget([]string{"a", "b", "c"}); err == nil {
+		t.Error("statusTarget with 3 args: expected error")
+	}
+}
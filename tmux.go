@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"os"
 	"os/exec"
 	"strconv"
@@ -20,8 +21,9 @@ func tmuxArgs(args ...string) []string {
 	return args
 }
 
-// TmuxCmd executes a tmux command and returns the output
-func TmuxCmd(args ...string) (string, error) {
+// runTmux executes a tmux command and returns its trimmed output.
+// Tests replace it with a fake tmux client.
+var runTmux = func(args ...string) (string, error) {
 	cmd := exec.Command("tmux", tmuxArgs(args...)...)
 	out, err := cmd.Output()
 	if err != nil {
@@ -30,6 +32,11 @@ func TmuxCmd(args ...string) (string, error) {
 	return strings.TrimSpace(string(out)), nil
 }
 
+// TmuxCmd executes a tmux command and returns the output
+func TmuxCmd(args ...string) (string, error) {
+	return runTmux(args...)
+}
+
 // TmuxCmdNoOutput executes a tmux command without capturing output
 func TmuxCmdNoOutput(args ...string) error {
 	cmd := exec.Command("tmux", tmuxArgs(args...)...)
@@ -46,9 +53,55 @@ func GetCurrentWindow() (string, error) {
 	return TmuxCmd("display-message", "-p", "#{window_index}")
 }
 
+// WindowRef identifies a window by session name, window index and window ID
+type WindowRef struct {
+	Session string
+	Window  string
+	ID      string // e.g. "@3", stable while the window exists
+}
+
+// GetCurrentWindowRef returns the current window's session, index and ID
+func GetCurrentWindowRef() (WindowRef, error) {
+	return GetWindowRefIn("")
+}
+
+// GetWindowRefIn returns the session, index and ID of a target window
+func GetWindowRefIn(target string) (WindowRef, error) {
+	args := append([]string{"display-message", "-p"}, targetArgs(target)...)
+	// Session name last: tmux turns control characters like tabs into "_" for
+	// non-UTF-8 clients, so the fields are split on ":" instead
+	out, err := TmuxCmd(append(args, "#{window_index}:#{window_id}:#{session_name}")...)
+	if err != nil {
+		return WindowRef{}, err
+	}
+	parts := strings.SplitN(out, ":", 3)
+	if len(parts) != 3 {
+		return WindowRef{}, fmt.Errorf("unexpected window format: %q", out)
+	}
+	return WindowRef{Session: parts[2], Window: parts[0], ID: parts[1]}, nil
+}
+
+// ListWindowIDs returns the IDs of every window on the server
+func ListWindowIDs() (map[string]bool, error) {
+	out, err := TmuxCmd("list-windows", "-a", "-F", "#{window_id}")
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]bool)
+	for _, id := range strings.Fields(out) {
+		ids[id] = true
+	}
+	return ids, nil
+}
+
 // GetCurrentPane returns the current pane ID (e.g., "%42")
 func GetCurrentPane() (string, error) {
-	return TmuxCmd("display-message", "-p", "#{pane_id}")
+	return GetActivePaneIn("")
+}
+
+// GetActivePaneIn returns the ID of a target window's active pane
+func GetActivePaneIn(target string) (string, error) {
+	return TmuxCmd(append(append([]string{"display-message", "-p"}, targetArgs(target)...), "#{pane_id}")...)
 }
 
 // GetActivePaneID returns the current pane's numeric ID
@@ -57,16 +110,31 @@ func GetActivePaneID() (int, error) {
 	if err != nil {
 		return 0, err
 	}
+	return parsePaneID(paneID)
+}
+
+// parsePaneID converts a pane ID like "%42" (or plain "42") to its number
+func parsePaneID(paneID string) (int, error) {
 	// pane_id is in format "%42", strip the % prefix
-	if len(paneID) > 0 && paneID[0] == '%' {
-		paneID = paneID[1:]
+	return strconv.Atoi(strings.TrimPrefix(paneID, "%"))
+}
+
+// targetArgs returns the -t flag for a window target, or nothing for the current window
+func targetArgs(target string) []string {
+	if target == "" {
+		return nil
 	}
-	return strconv.Atoi(paneID)
+	return []string{"-t", target}
 }
 
 // GetWindowLayout returns the current window layout string
 func GetWindowLayout() (string, error) {
-	return TmuxCmd("display-message", "-p", "#{window_layout}")
+	return GetWindowLayoutIn("")
+}
+
+// GetWindowLayoutIn returns the layout string of a target window
+func GetWindowLayoutIn(target string) (string, error) {
+	return TmuxCmd(append(append([]string{"display-message", "-p"}, targetArgs(target)...), "#{window_layout}")...)
 }
 
 // GetPaneCount returns the number of panes in the current window
@@ -80,11 +148,17 @@ func GetPaneCount() (int, error) {
 
 // GetWindowSize returns window width and height
 func GetWindowSize() (width, height int, err error) {
-	w, err := TmuxCmd("display-message", "-p", "#{window_width}")
+	return GetWindowSizeIn("")
+}
+
+// GetWindowSizeIn returns the width and height of a target window
+func GetWindowSizeIn(target string) (width, height int, err error) {
+	display := append([]string{"display-message", "-p"}, targetArgs(target)...)
+	w, err := TmuxCmd(append(display, "#{window_width}")...)
 	if err != nil {
 		return 0, 0, err
 	}
-	h, err := TmuxCmd("display-message", "-p", "#{window_height}")
+	h, err := TmuxCmd(append(display, "#{window_height}")...)
 	if err != nil {
 		return 0, 0, err
 	}
@@ -101,9 +175,45 @@ func GetWindowSize() (width, height int, err error) {
 	return width, height, nil
 }
 
+// IsWindowZoomed reports whether a pane in the current window is natively zoomed (resize-pane -Z)
+func IsWindowZoomed() (bool, error) {
+	return IsWindowZoomedIn("")
+}
+
+// IsWindowZoomedIn reports whether a pane in a target window is natively zoomed
+func IsWindowZoomedIn(target string) (bool, error) {
+	out, err := TmuxCmd(append(append([]string{"display-message", "-p"}, targetArgs(target)...), "#{window_zoomed_flag}")...)
+	if err != nil {
+		return false, err
+	}
+	return out == "1", nil
+}
+
+// ZoomActivePaneIn natively zooms the active pane of a target window
+func ZoomActivePaneIn(target string) error {
+	debugf("resize-pane -Z %v", targetArgs(target))
+	return TmuxCmdNoOutput(append([]string{"resize-pane", "-Z"}, targetArgs(target)...)...)
+}
+
 // SelectLayout applies a layout string to the current window
 func SelectLayout(layout string) error {
-	return TmuxCmdNoOutput("select-layout", layout)
+	return SelectLayoutIn("", layout)
+}
+
+// SelectLayoutIn applies a layout string to a target window
+func SelectLayoutIn(target, layout string) error {
+	return TmuxCmdNoOutput(append(append([]string{"select-layout"}, targetArgs(target)...), layout)...)
+}
+
+// SwapPane swaps the positions of two panes, keeping the active position
+func SwapPane(src, dst string) error {
+	debugf("swap-pane -d -s %s -t %s", src, dst)
+	return TmuxCmdNoOutput("swap-pane", "-d", "-s", src, "-t", dst)
+}
+
+// SelectPane makes a pane active
+func SelectPane(paneID string) error {
+	return TmuxCmdNoOutput("select-pane", "-t", paneID)
 }
 
 // ResizePane resizes the current pane to the specified dimensions
@@ -121,19 +231,29 @@ func DisplayMessage(msg string) error {
 
 // PaneInfo holds information about a pane
 type PaneInfo struct {
-	ID     string
-	Index  int
-	Width  int
-	Height int
-	Left   int
-	Top    int
-	Active bool
+	ID             string
+	Index          int
+	Width          int
+	Height         int
+	Left           int
+	Top            int
+	Active         bool
+	Pinned         bool   // @focus-zoom-pinned is on for the pane
+	CurrentCommand string // #{pane_current_command}
+	Title          string // #{pane_title}
 }
 
 // GetPanes returns info about all panes in current window
 func GetPanes() ([]PaneInfo, error) {
-	// Format: id:index:width:height:left:top:active
-	out, err := TmuxCmd("list-panes", "-F", "#{pane_id}:#{pane_index}:#{pane_width}:#{pane_height}:#{pane_left}:#{pane_top}:#{pane_active}")
+	return GetPanesIn("")
+}
+
+// GetPanesIn returns info about all panes in a target window
+func GetPanesIn(target string) ([]PaneInfo, error) {
+	// Format: id:index:width:height:left:top:active:pinned:command:title
+	// The title goes last since it is free text and may contain colons
+	args := append([]string{"list-panes"}, targetArgs(target)...)
+	out, err := TmuxCmd(append(args, "-F", "#{pane_id}:#{pane_index}:#{pane_width}:#{pane_height}:#{pane_left}:#{pane_top}:#{pane_active}:#{@focus-zoom-pinned}:#{pane_current_command}:#{pane_title}")...)
 	if err != nil {
 		return nil, err
 	}
@@ -143,8 +263,8 @@ func GetPanes() ([]PaneInfo, error) {
 		if line == "" {
 			continue
 		}
-		parts := strings.Split(line, ":")
-		if len(parts) != 7 {
+		parts := strings.SplitN(line, ":", 10)
+		if len(parts) != 10 {
 			continue
 		}
 
@@ -156,19 +276,31 @@ func GetPanes() ([]PaneInfo, error) {
 		active := parts[6] == "1"
 
 		panes = append(panes, PaneInfo{
-			ID:     parts[0],
-			Index:  index,
-			Width:  width,
-			Height: height,
-			Left:   left,
-			Top:    top,
-			Active: active,
+			ID:             parts[0],
+			Index:          index,
+			Width:          width,
+			Height:         height,
+			Left:           left,
+			Top:            top,
+			Active:         active,
+			Pinned:         isOptionOn(parts[7]),
+			CurrentCommand: parts[8],
+			Title:          parts[9],
 		})
 	}
 
 	return panes, nil
 }
 
+// isOptionOn reports whether a tmux flag option value is set
+func isOptionOn(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "on", "1", "yes", "true":
+		return true
+	}
+	return false
+}
+
 // ResizePaneWidth resizes a pane's width only
 func ResizePaneWidth(paneID string, width int) error {
 	debugf("resize-pane -t %s -x %d", paneID, width)
@@ -181,16 +313,166 @@ func ResizePaneHeight(paneID string, height int) error {
 	return TmuxCmdNoOutput("resize-pane", "-t", paneID, "-y", strconv.Itoa(height))
 }
 
-// GetZoomPercent returns the configured zoom percentage from tmux option @focus-zoom-percent
-// Falls back to DefaultZoomPercent if not set or invalid
-func GetZoomPercent() int {
-	out, err := TmuxCmd("show-option", "-gqv", "@focus-zoom-percent")
-	if err != nil || out == "" {
-		return DefaultZoomPercent
+// optionScopes returns the show-option flags for each scope an option can be
+// set at, from most to least specific: pane, window, session and global.
+func optionScopes(paneID string) [][]string {
+	var target []string
+	if paneID != "" {
+		target = []string{"-t", paneID}
+	}
+	return [][]string{
+		append([]string{"show-option", "-pqv"}, target...),
+		append([]string{"show-option", "-wqv"}, target...),
+		append([]string{"show-option", "-qv"}, target...),
+		{"show-option", "-gqv"},
+	}
+}
+
+// lookupOption returns the first non-empty value of a tmux option for which
+// valid returns true, checking pane, window, session then global scope.
+// An empty paneID means the current pane. valid may be nil.
+func lookupOption(name, paneID string, valid func(string) bool) (string, bool) {
+	// tmux resolves the scopes itself in one call; only an invalid value
+	// needs each scope checked in turn
+	if values, err := GetFormats(paneID, name); err == nil {
+		if values[0] == "" {
+			return "", false
+		}
+		if valid == nil || valid(values[0]) {
+			return values[0], true
+		}
+	}
+	return lookupOptionIn(optionScopes(paneID), name, valid)
+}
+
+// lookupOptionIn is lookupOption over a subset of the option scopes
+func lookupOptionIn(scopes [][]string, name string, valid func(string) bool) (string, bool) {
+	for _, args := range scopes {
+		out, err := TmuxCmd(append(args, name)...)
+		if err != nil || out == "" {
+			continue
+		}
+		if valid != nil && !valid(out) {
+			debugf("Ignoring invalid %s value %q", name, out)
+			continue
+		}
+		return out, true
+	}
+	return "", false
+}
+
+// GetOption returns a tmux option resolved for a pane with precedence
+// pane > window > session > global, or "" if it isn't set anywhere.
+func GetOption(name, paneID string) string {
+	value, _ := lookupOption(name, paneID, nil)
+	return value
+}
+
+// GetFormats expands tmux formats such as "window_id" or "@focus-zoom-percent" for
+// a target (or the current pane) in one display-message call. Options resolve
+// like GetOption. Values are quoted with q: so "|" can separate them.
+func GetFormats(target string, names ...string) ([]string, error) {
+	var format strings.Builder
+	for _, name := range names {
+		format.WriteString("#{q:" + name + "}|")
+	}
+	out, err := TmuxCmd(append(append([]string{"display-message", "-p"}, targetArgs(target)...), format.String())...)
+	if err != nil {
+		return nil, err
+	}
+	values := splitQuoted(out)
+	if len(values) != len(names)+1 {
+		return nil, fmt.Errorf("unexpected format output: %q", out)
+	}
+	return values[:len(names)], nil
+}
+
+// splitQuoted splits q:-quoted values on unescaped "|" and removes the escapes
+func splitQuoted(s string) []string {
+	values := []string{""}
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			values[len(values)-1] += string(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '|':
+			values = append(values, "")
+		default:
+			values[len(values)-1] += string(r)
+		}
 	}
-	percent, err := strconv.Atoi(out)
-	if err != nil || percent < 10 || percent > 95 {
+	return values
+}
+
+// GetScopedOption returns an option's value at one scope only, e.g. "-w" for the
+// current window or "-g" for global
+func GetScopedOption(scope, name string) (string, error) {
+	return GetScopedOptionIn(scope, "", name)
+}
+
+// GetScopedOptionIn is GetScopedOption for a target pane or window
+func GetScopedOptionIn(scope, target, name string) (string, error) {
+	return TmuxCmd(append(append([]string{"show-option", scope + "qv"}, targetArgs(target)...), name)...)
+}
+
+// SetScopedOption sets an option at a scope, e.g. "-w" for the current window
+func SetScopedOption(scope, name, value string) error {
+	return SetScopedOptionIn(scope, "", name, value)
+}
+
+// SetScopedOptionIn is SetScopedOption for a target pane or window
+func SetScopedOptionIn(scope, target, name, value string) error {
+	debugf("set-option %s %v %s %s", scope, targetArgs(target), name, value)
+	return TmuxCmdNoOutput(append(append([]string{"set-option", scope}, targetArgs(target)...), name, value)...)
+}
+
+// UnsetScopedOption removes an option at a scope so a less specific one applies
+func UnsetScopedOption(scope, name string) error {
+	return UnsetScopedOptionIn(scope, "", name)
+}
+
+// UnsetScopedOptionIn is UnsetScopedOption for a target pane or window
+func UnsetScopedOptionIn(scope, target, name string) error {
+	debugf("set-option %su %v %s", scope, targetArgs(target), name)
+	return TmuxCmdNoOutput(append(append([]string{"set-option", scope + "u"}, targetArgs(target)...), name)...)
+}
+
+// GetZoomPercent returns the configured zoom percentage for a pane from tmux option
+// @focus-zoom-percent, resolved with GetOption's precedence. Invalid values are
+// skipped in favour of a less specific scope. An empty paneID means the current pane.
+// Falls back to DefaultZoomPercent if not set or invalid
+func GetZoomPercent(paneID string) int {
+	return zoomPercent(lookupOption("@focus-zoom-percent", paneID, validZoomPercentValue))
+}
+
+// GetWindowZoomPercent returns @focus-zoom-percent for the current window,
+// ignoring any pane-scope value. It's the value grow, shrink and cycle change.
+func GetWindowZoomPercent() int {
+	return zoomPercent(lookupOptionIn(optionScopes("")[1:], "@focus-zoom-percent", validZoomPercentValue))
+}
+
+// validZoomPercentValue reports whether an option value is a valid zoom percentage
+func validZoomPercentValue(value string) bool {
+	percent, err := strconv.Atoi(value)
+	return err == nil && validZoomPercent(percent)
+}
+
+// zoomPercent converts a looked up @focus-zoom-percent, or returns DefaultZoomPercent if unset
+func zoomPercent(value string, ok bool) int {
+	if !ok {
 		return DefaultZoomPercent
 	}
+	percent, _ := strconv.Atoi(value)
 	return percent
 }
+
+// GetIgnoreCommands returns the command patterns from @focus-zoom-ignore-commands
+// for a pane's window. Patterns are separated by commas or spaces and use shell glob syntax.
+func GetIgnoreCommands(paneID string) []string {
+	return strings.FieldsFunc(GetOption("@focus-zoom-ignore-commands", paneID), func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// fakeTmux answers show-option, list-panes and option formats from memory so
+// option and pane handling can be tested without a tmux server
+type fakeTmux struct {
+	// options maps a scope ("pane", "window", "session", "global") to option values
+	options map[string]map[string]string
+	// panes is the list-panes output
+	panes string
+	calls [][]string
+}
+
+// newFakeTmux installs a fake tmux client for the duration of the test
+func newFakeTmux(t *testing.T) *fakeTmux {
+	t.Helper()
+	fake := &fakeTmux{options: map[string]map[string]string{}}
+	orig := runTmux
+	runTmux = fake.run
+	t.Cleanup(func() { runTmux = orig })
+	return fake
+}
+
+// set sets an option at a scope
+func (f *fakeTmux) set(scope, name, value string) {
+	if f.options[scope] == nil {
+		f.options[scope] = map[string]string{}
+	}
+	f.options[scope][name] = value
+}
+
+func (f *fakeTmux) run(args ...string) (string, error) {
+	f.calls = append(f.calls, args)
+	if len(args) > 0 && args[0] == "list-panes" {
+		return f.panes, nil
+	}
+	if len(args) > 0 && args[0] == "display-message" {
+		return f.format(args[len(args)-1]), nil
+	}
+	if len(args) < 3 || args[0] != "show-option" {
+		return "", fmt.Errorf("fake tmux: unsupported command %q", strings.Join(args, " "))
+	}
+
+	scope := "session"
+	switch {
+	case strings.Contains(args[1], "p"):
+		scope = "pane"
+	case strings.Contains(args[1], "w"):
+		scope = "window"
+	case strings.Contains(args[1], "g"):
+		scope = "global"
+	}
+	return f.options[scope][args[len(args)-1]], nil
+}
+
+// format expands #{q:@option} formats the way tmux does, taking the most
+// specific scope an option is set at
+func (f *fakeTmux) format(format string) string {
+	return regexp.MustCompile(`#\{q:([^}]*)\}`).ReplaceAllStringFunc(format, func(m string) string {
+		name := m[len("#{q:") : len(m)-1]
+		for _, scope := range []string{"pane", "window", "session", "global"} {
+			if value, ok := f.options[scope][name]; ok {
+				return regexp.MustCompile("[|&;<>()$`\\\\\"'*?\\[# =%]").ReplaceAllString(value, `\$0`)
+			}
+		}
+		return ""
+	})
+}
+
+func TestGetOptionPrecedence(t *testing.T) {
+	fake := newFakeTmux(t)
+	name := "@focus-zoom-percent"
+
+	scopes := []string{"global", "session", "window", "pane"}
+	values := []string{"50", "60", "70", "80"}
+	for i, scope := range scopes {
+		fake.set(scope, name, values[i])
+		if got := GetOption(name, "%1"); got != values[i] {
+			t.Errorf("with %s set: GetOption = %q, want %q", scope, got, values[i])
+		}
+	}
+}
+
+func TestGetOptionTarget(t *testing.T) {
+	fake := newFakeTmux(t)
+	GetOption("@focus-zoom-ignore-commands", "%7")
+	if len(fake.calls) != 1 {
+		t.Errorf("expected one tmux call, got %v", fake.calls)
+	}
+
+	// An invalid value is skipped by checking each scope in turn
+	fake.calls = nil
+	fake.set("global", "@focus-zoom-percent", "big")
+	GetZoomPercent("%7")
+	for _, call := range fake.calls {
+		if call[1] == "-gqv" {
+			continue
+		}
+		if len(call) != 5 || call[2] != "-t" || call[3] != "%7" {
+			t.Errorf("expected -t %%7 in %v", call)
+		}
+	}
+	if len(fake.calls) != 5 {
+		t.Errorf("expected a format call and 4 show-option calls, got %d", len(fake.calls))
+	}
+}
+
+func TestGetZoomPercent(t *testing.T) {
+	fake := newFakeTmux(t)
+
+	if got := GetZoomPercent("%1"); got != DefaultZoomPercent {
+		t.Errorf("unset: got %d, want %d", got, DefaultZoomPercent)
+	}
+
+	fake.set("global", "@focus-zoom-percent", "55")
+	fake.set("session", "@focus-zoom-percent", "75")
+	if got := GetZoomPercent("%1"); got != 75 {
+		t.Errorf("session over global: got %d, want 75", got)
+	}
+
+	// Invalid values fall through to the next scope
+	fake.set("window", "@focus-zoom-percent", "200")
+	fake.set("pane", "@focus-zoom-percent", "big")
+	if got := GetZoomPercent("%1"); got != 75 {
+		t.Errorf("invalid window and pane values: got %d, want 75", got)
+	}
+}
+
+func TestGetPanesFreeTextFields(t *testing.T) {
+	fake := newFakeTmux(t)
+	fake.panes = "%0:0:100:50:0:0:1::vim:host: ~/src\n%1:1:99:50:101:0:0:on:htop:"
+
+	panes, err := GetPanes()
+	if err != nil {
+		t.Fatalf("GetPanes: %v", err)
+	}
+	if len(panes) != 2 {
+		t.Fatalf("expected 2 panes, got %+v", panes)
+	}
+	if panes[0].CurrentCommand != "vim" || panes[0].Title != "host: ~/src" || !panes[0].Active {
+		t.Errorf("pane 0: got %+v", panes[0])
+	}
+	if panes[1].CurrentCommand != "htop" || panes[1].Title != "" || !panes[1].Pinned || panes[1].Left != 101 {
+		t.Errorf("pane 1: got %+v", panes[1])
+	}
+}
+
+func TestSplitQuoted(t *testing.T) {
+	// display-message output for four values: "x|y z", "fg=#fff", `\` and an unset option
+	got := splitQuoted(`x\|y\ z|fg\=\#fff|\\||`)
+	want := []string{"x|y z", "fg=#fff", `\`, "", ""}
+	if strings.Join(got, ",") != strings.Join(want, ",") || len(got) != len(want) {
+		t.Errorf("splitQuoted = %q, want %q", got, want)
+	}
+}
+
+func TestGetIgnoreCommands(t *testing.T) {
+	fake := newFakeTmux(t)
+	fake.set("global", "@focus-zoom-ignore-commands", "tail")
+	fake.set("window", "@focus-zoom-ignore-commands", "htop, watch*")
+
+	got := GetIgnoreCommands("%1")
+	if strings.Join(got, "|") != "htop|watch*" {
+		t.Errorf("GetIgnoreCommands = %q, want [htop watch*]", got)
+	}
+}
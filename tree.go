@@ -0,0 +1,105 @@
+package main
+
+// Walk visits node and all of its descendants depth-first, parents before
+// children. If fn returns false the children of that node are skipped.
+func (node *LayoutNode) Walk(fn func(n *LayoutNode) bool) {
+	if node == nil || !fn(node) {
+		return
+	}
+	for _, child := range node.Children {
+		child.Walk(fn)
+	}
+}
+
+// Leaves returns the leaf panes of the tree in layout order
+func (node *LayoutNode) Leaves() []*LayoutNode {
+	var leaves []*LayoutNode
+	node.Walk(func(n *LayoutNode) bool {
+		if n.SplitType == SplitNone {
+			leaves = append(leaves, n)
+		}
+		return true
+	})
+	return leaves
+}
+
+// PanePath returns the child indices leading from node to the pane with paneID.
+// An empty path means node itself is the pane. ok is false if the pane is not in the tree.
+func (node *LayoutNode) PanePath(paneID int) (path []int, ok bool) {
+	return node.panePath(paneID, []int{})
+}
+
+// panePath is PanePath with the indices leading to node already in prefix.
+// Siblings share prefix's backing array; only the path that's found is returned.
+func (node *LayoutNode) panePath(paneID int, prefix []int) ([]int, bool) {
+	if node == nil {
+		return nil, false
+	}
+	if node.SplitType == SplitNone {
+		return prefix, node.PaneID == paneID
+	}
+	for i, child := range node.Children {
+		if path, found := child.panePath(paneID, append(prefix, i)); found {
+			return path, true
+		}
+	}
+	return nil, false
+}
+
+// FindPane returns the leaf for paneID and its ancestors, root first.
+// Returns nil if the pane is not in the tree.
+func (node *LayoutNode) FindPane(paneID int) (pane *LayoutNode, ancestors []*LayoutNode) {
+	path, ok := node.PanePath(paneID)
+	if !ok {
+		return nil, nil
+	}
+	return node.NodeAt(path)
+}
+
+// NodeAt follows path from node and returns the node it ends on along with
+// the ancestors visited on the way, root first. Returns nil for an invalid path.
+func (node *LayoutNode) NodeAt(path []int) (target *LayoutNode, ancestors []*LayoutNode) {
+	current := node
+	for _, idx := range path {
+		if current == nil || idx < 0 || idx >= len(current.Children) {
+			return nil, nil
+		}
+		ancestors = append(ancestors, current)
+		current = current.Children[idx]
+	}
+	return current, ancestors
+}
+
+// paneIDs returns the pane IDs of the tree's leaves in layout order
+func (node *LayoutNode) paneIDs() []int {
+	var ids []int
+	for _, leaf := range node.Leaves() {
+		ids = append(ids, leaf.PaneID)
+	}
+	return ids
+}
+
+// remapPanes gives leaves whose pane is not in live the unused live IDs, in
+// layout order. Panes that still exist keep their place in the tree.
+func remapPanes(node *LayoutNode, live []int) {
+	inTree := make(map[int]bool)
+	for _, id := range node.paneIDs() {
+		inTree[id] = true
+	}
+	liveSet := make(map[int]bool)
+	var unused []int
+	for _, id := range live {
+		liveSet[id] = true
+		if !inTree[id] {
+			unused = append(unused, id)
+		}
+	}
+
+	for _, leaf := range node.Leaves() {
+		if liveSet[leaf.PaneID] || len(unused) == 0 {
+			continue
+		}
+		leaf.PaneID = unused[0]
+		unused = unused[1:]
+	}
+}
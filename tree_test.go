@@ -0,0 +1,154 @@
+package main
+
+import (
+	"testing"
+)
+
+const cortexLayout = "b2d9,255x61,0,0{84x61,0,0[84x30,0,0,26,84x30,0,31,41],85x61,85,0,36,84x61,171,0,42}"
+
+func TestLeaves(t *testing.T) {
+	node, err := ParseLayout(cortexLayout)
+	if err != nil {
+		t.Fatalf("ParseLayout failed: %v", err)
+	}
+
+	var ids []int
+	for _, leaf := range node.Leaves() {
+		ids = append(ids, leaf.PaneID)
+	}
+
+	expected := []int{26, 41, 36, 42}
+	if len(ids) != len(expected) {
+		t.Fatalf("Leaves: got %v, want %v", ids, expected)
+	}
+	for i := range expected {
+		if ids[i] != expected[i] {
+			t.Errorf("Leaves[%d]: got %d, want %d", i, ids[i], expected[i])
+		}
+	}
+}
+
+func TestWalkSkipsChildren(t *testing.T) {
+	node, err := ParseLayout(cortexLayout)
+	if err != nil {
+		t.Fatalf("ParseLayout failed: %v", err)
+	}
+
+	// Don't descend into the vertical split - its two panes should be skipped
+	visited := 0
+	node.Walk(func(n *LayoutNode) bool {
+		visited++
+		return n.SplitType != SplitVertical
+	})
+
+	// root, col0 split, pane 36, pane 42
+	if visited != 4 {
+		t.Errorf("Walk visited %d nodes, want 4", visited)
+	}
+}
+
+func TestPanePath(t *testing.T) {
+	node, err := ParseLayout(cortexLayout)
+	if err != nil {
+		t.Fatalf("ParseLayout failed: %v", err)
+	}
+
+	tests := []struct {
+		paneID int
+		path   []int
+		found  bool
+	}{
+		{paneID: 26, path: []int{0, 0}, found: true},
+		{paneID: 41, path: []int{0, 1}, found: true},
+		{paneID: 36, path: []int{1}, found: true},
+		{paneID: 42, path: []int{2}, found: true},
+		{paneID: 99, found: false},
+	}
+
+	for _, tc := range tests {
+		path, ok := node.PanePath(tc.paneID)
+		if ok != tc.found {
+			t.Errorf("PanePath(%d) found=%v, want %v", tc.paneID, ok, tc.found)
+			continue
+		}
+		if len(path) != len(tc.path) {
+			t.Errorf("PanePath(%d) = %v, want %v", tc.paneID, path, tc.path)
+			continue
+		}
+		for i := range path {
+			if path[i] != tc.path[i] {
+				t.Errorf("PanePath(%d) = %v, want %v", tc.paneID, path, tc.path)
+				break
+			}
+		}
+	}
+}
+
+func TestFindPane(t *testing.T) {
+	node, err := ParseLayout(cortexLayout)
+	if err != nil {
+		t.Fatalf("ParseLayout failed: %v", err)
+	}
+
+	pane, ancestors := node.FindPane(41)
+	if pane == nil {
+		t.Fatal("FindPane(41) returned nil")
+	}
+	if pane.PaneID != 41 || pane.Y != 31 {
+		t.Errorf("FindPane(41): got pane %d at y=%d, want pane 41 at y=31", pane.PaneID, pane.Y)
+	}
+	if len(ancestors) != 2 || ancestors[0] != node || ancestors[1] != node.Children[0] {
+		t.Errorf("FindPane(41): unexpected ancestors %v", ancestors)
+	}
+
+	if pane, _ := node.FindPane(99); pane != nil {
+		t.Errorf("FindPane(99): expected nil, got pane %d", pane.PaneID)
+	}
+}
+
+// TestApplyNestedZoom verifies the vertical split containing the active pane is zoomed too
+func TestApplyNestedZoom(t *testing.T) {
+	node, err := ParseLayout(cortexLayout)
+	if err != nil {
+		t.Fatalf("ParseLayout failed: %v", err)
+	}
+
+	zoomed := ApplyZoomToLayout(node, 41, DefaultZoomPercent)
+	path, ok := zoomed.PanePath(41)
+	if !ok {
+		t.Fatal("PanePath(41) not found")
+	}
+	applyNestedZoom(zoomed, path, zoomOptions{Percent: DefaultZoomPercent})
+
+	col0 := zoomed.Children[0]
+	p1, p2 := col0.Children[0], col0.Children[1]
+
+	// 60 rows available, 65% = 39 for the active pane
+	if p2.Height != 39 || p1.Height != 21 {
+		t.Errorf("Col0 heights: got p1=%d p2=%d, want p1=21 p2=39", p1.Height, p2.Height)
+	}
+	if p2.Y != p1.Y+p1.Height+1 {
+		t.Errorf("P2 y: got %d, want %d", p2.Y, p1.Y+p1.Height+1)
+	}
+	if p1.Width != col0.Width || p2.Width != col0.Width {
+		t.Errorf("Col0 pane widths should match column width %d: got %d, %d", col0.Width, p1.Width, p2.Width)
+	}
+}
+
+func TestRemapPanes(t *testing.T) {
+	node, err := ParseLayout(cortexLayout)
+	if err != nil {
+		t.Fatalf("ParseLayout failed: %v", err)
+	}
+
+	// Pane 41 was closed and pane 50 opened; 26, 36 and 42 keep their place
+	remapPanes(node, []int{26, 36, 42, 50})
+
+	got := node.paneIDs()
+	expected := []int{26, 50, 36, 42}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Fatalf("paneIDs after remap: got %v, want %v", got, expected)
+		}
+	}
+}